@@ -69,6 +69,13 @@ type ListURL struct {
 	Rental  string `json:"rental"`
 }
 
+// ActressIndex is an index of actresses by initial
+type ActressIndex struct {
+	// Initials are the indexed initials in gojūon order
+	Initials  []string
+	Actresses map[string][]Actress
+}
+
 // ActressOptions specifies the optional parameters to various List methods
 type ActressOptions struct {
 	APIID       string `json:"api_id" url:"api_id"`
@@ -117,20 +124,21 @@ type internalActressOptions struct {
 }
 
 func (r *actressRoot) populatePageValues(res *Response) {
+	res.ResultStatus = r.Result.Status.Int()
 	res.FirstPosition = r.Result.FirstPosition.Int()
 	res.ResultCount = r.Result.ResultCount.Int()
 	res.TotalCount = r.Result.TotalCount.Int()
 	res.Parameters = r.Request.Parameters.Convert()
 }
 
-func (s *ActressesServiceOp) list(ctx context.Context, path string) (actressResult, *Response, error) {
+func (s *ActressesServiceOp) list(ctx context.Context, path string, opt ListOptions) (actressResult, *Response, error) {
 	req, err := s.client.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return actressResult{}, nil, err
 	}
 
 	var root actressRoot
-	resp, err := s.client.Do(ctx, req, &root)
+	resp, err := s.client.do(ctx, &Call{Request: req, Options: listOptions(opt), Value: &root})
 	if err != nil {
 		return actressResult{}, resp, err
 	}
@@ -162,7 +170,7 @@ func (s *ActressesServiceOp) Unmarshal(ctx context.Context, opt *ActressOptions,
 	}
 	var res actressResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return r, err
 	}
@@ -175,6 +183,71 @@ func (s *ActressesServiceOp) Unmarshal(ctx context.Context, opt *ActressOptions,
 	return r, err
 }
 
+// ResolveActress returns the actress whose name or reading best matches name, as scored by kana.Match.
+// Unless opt sets Keyword or Initial, the actresses are searched by name as keyword;
+// a romanized name therefore needs an Initial to narrow the search.
+// ErrNotResolved is returned if no actress scores ResolveThreshold.
+func ResolveActress(ctx context.Context, c *Client, opt *ActressOptions, name string) (Actress, *Response, error) {
+	var o ActressOptions
+	if opt != nil {
+		o = *opt
+	}
+	resolvePage(&o.Hits, &o.Offset)
+	if o.Keyword == "" && o.Initial == "" {
+		o.Keyword = name
+	}
+
+	var best Actress
+	var resp *Response
+	m := &matcher{name: name}
+	err := c.resolve(ctx, &o, m, func(ctx context.Context) (*Response, error) {
+		as, r, err := c.Actresses.List(ctx, &o)
+		resp = r
+		for _, a := range as {
+			if m.consider(a.Name, a.Ruby) {
+				best = a
+			}
+		}
+		return r, err
+	})
+	if err != nil {
+		return Actress{}, resp, err
+	}
+	return best, resp, nil
+}
+
+// IndexActresses fetches every page of actresses of opt for each initial of the gojūon table, or only for opt.Initial if it is set,
+// running at most concurrency initials at once (DefaultIndexConcurrency if 0).
+// An *InitialError is returned if opt.Initial is not a kana.
+func IndexActresses(ctx context.Context, c *Client, opt *ActressOptions, concurrency int) (ActressIndex, error) {
+	var o ActressOptions
+	if opt != nil {
+		o = *opt
+	}
+	initials, lists, err := fetchIndex(ctx, o.Initial, concurrency, func(ctx context.Context, initial string) (interface{}, error) {
+		po := o
+		po.Initial = initial
+		indexPage(&po.Hits, &po.Offset)
+
+		var as []Actress
+		err := c.Paginate(ctx, &po, func(ctx context.Context) (*Response, error) {
+			page, r, err := c.Actresses.List(ctx, &po)
+			as = append(as, page...)
+			return r, err
+		})
+		return as, err
+	})
+	if err != nil {
+		return ActressIndex{}, err
+	}
+
+	idx := ActressIndex{Initials: initials, Actresses: make(map[string][]Actress, len(lists))}
+	for initial, l := range lists {
+		idx.Actresses[initial] = l.([]Actress)
+	}
+	return idx, nil
+}
+
 // Next updates offset
 func (o *ActressOptions) Next() (err error) {
 	o.Offset, err = nextOffset(o.Hits, o.Offset)
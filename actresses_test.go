@@ -257,3 +257,24 @@ func TestActresses_First(t *testing.T) {
 		t.Errorf("Response.FirstPosition returned %+v, expected %+v", r.FirstPosition, re.FirstPosition)
 	}
 }
+
+func TestResolveActress(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+actressBasePath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		if kw := r.URL.Query().Get("keyword"); kw != `あいうち はるな` {
+			t.Errorf("Request keyword = %q, expected %q", kw, `あいうち はるな`)
+		}
+		fmt.Fprint(w, testActressesRequest)
+	})
+
+	actual, _, err := ResolveActress(ctx, client, nil, `あいうち はるな`)
+	if err != nil {
+		t.Fatalf("ResolveActress returned error: %v", err)
+	}
+	if actual.ID != `1038122` {
+		t.Errorf("ResolveActress returned %+v, expected ID 1038122", actual)
+	}
+}
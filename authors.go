@@ -64,6 +64,13 @@ type Author struct {
 	FloorCode   string
 }
 
+// AuthorIndex is an index of authors by initial
+type AuthorIndex struct {
+	// Initials are the indexed initials in gojūon order
+	Initials []string
+	Authors  map[string][]Author
+}
+
 // AuthorOptions specifies the optional parameters to various List methods
 type AuthorOptions struct {
 	APIID       string `json:"api_id" url:"api_id"`
@@ -88,20 +95,21 @@ type internalAuthorOptions struct {
 }
 
 func (r *authorRoot) populatePageValues(res *Response) {
+	res.ResultStatus = r.Result.Status.Int()
 	res.FirstPosition = r.Result.FirstPosition.Int()
 	res.ResultCount = r.Result.ResultCount.Int()
 	res.TotalCount = r.Result.TotalCount.Int()
 	res.Parameters = r.Request.Parameters.Convert()
 }
 
-func (s *AuthorsServiceOp) list(ctx context.Context, path string) (authorResult, *Response, error) {
+func (s *AuthorsServiceOp) list(ctx context.Context, path string, opt ListOptions) (authorResult, *Response, error) {
 	req, err := s.client.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return authorResult{}, nil, err
 	}
 
 	var root authorRoot
-	resp, err := s.client.Do(ctx, req, &root)
+	resp, err := s.client.do(ctx, &Call{Request: req, Options: listOptions(opt), Value: &root})
 	if err != nil {
 		return authorResult{}, resp, err
 	}
@@ -126,7 +134,7 @@ func (s *AuthorsServiceOp) List(ctx context.Context, opt *AuthorOptions) ([]Auth
 	}
 	var res authorResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return nil, r, err
 	}
@@ -155,7 +163,7 @@ func (s *AuthorsServiceOp) Unmarshal(ctx context.Context, opt *AuthorOptions, ou
 	}
 	var res authorResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return r, err
 	}
@@ -168,6 +176,66 @@ func (s *AuthorsServiceOp) Unmarshal(ctx context.Context, opt *AuthorOptions, ou
 	return r, err
 }
 
+// ResolveAuthor returns the author of the floor whose name or reading best matches name, as scored by kana.Match,
+// searching every page of opt. ErrNotResolved is returned if no author scores ResolveThreshold.
+func ResolveAuthor(ctx context.Context, c *Client, opt *AuthorOptions, name string) (Author, *Response, error) {
+	var o AuthorOptions
+	if opt != nil {
+		o = *opt
+	}
+	resolvePage(&o.Hits, &o.Offset)
+
+	var best Author
+	var resp *Response
+	m := &matcher{name: name}
+	err := c.resolve(ctx, &o, m, func(ctx context.Context) (*Response, error) {
+		as, r, err := c.Authors.List(ctx, &o)
+		resp = r
+		for _, a := range as {
+			if m.consider(a.Name, a.Ruby) {
+				best = a
+			}
+		}
+		return r, err
+	})
+	if err != nil {
+		return Author{}, resp, err
+	}
+	return best, resp, nil
+}
+
+// IndexAuthors fetches every page of authors of opt for each initial of the gojūon table, or only for opt.Initial if it is set,
+// running at most concurrency initials at once (DefaultIndexConcurrency if 0).
+// An *InitialError is returned if opt.Initial is not a kana.
+func IndexAuthors(ctx context.Context, c *Client, opt *AuthorOptions, concurrency int) (AuthorIndex, error) {
+	var o AuthorOptions
+	if opt != nil {
+		o = *opt
+	}
+	initials, lists, err := fetchIndex(ctx, o.Initial, concurrency, func(ctx context.Context, initial string) (interface{}, error) {
+		po := o
+		po.Initial = initial
+		indexPage(&po.Hits, &po.Offset)
+
+		var as []Author
+		err := c.Paginate(ctx, &po, func(ctx context.Context) (*Response, error) {
+			page, r, err := c.Authors.List(ctx, &po)
+			as = append(as, page...)
+			return r, err
+		})
+		return as, err
+	})
+	if err != nil {
+		return AuthorIndex{}, err
+	}
+
+	idx := AuthorIndex{Initials: initials, Authors: make(map[string][]Author, len(lists))}
+	for initial, l := range lists {
+		idx.Authors[initial] = l.([]Author)
+	}
+	return idx, nil
+}
+
 // Next updates offset
 func (o *AuthorOptions) Next() (err error) {
 	o.Offset, err = nextOffset(o.Hits, o.Offset)
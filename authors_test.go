@@ -196,3 +196,28 @@ func TestAuthors_First(t *testing.T) {
 		t.Errorf("Response.FirstPosition returned %+v, expected %+v", r.FirstPosition, re.FirstPosition)
 	}
 }
+
+func TestResolveAuthor(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+AuthorBasePath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, testAuthorsRequest)
+	})
+
+	for _, name := range []string{`安東 みきえ`, `ando mikie`} {
+		actual, _, err := ResolveAuthor(ctx, client, &AuthorOptions{FloorID: "72"}, name)
+		if err != nil {
+			t.Errorf("ResolveAuthor(%q) returned error: %v", name, err)
+			continue
+		}
+		if actual.AuthorID != `217781` {
+			t.Errorf("ResolveAuthor(%q) returned %+v, expected AuthorID 217781", name, actual)
+		}
+	}
+
+	if _, _, err := ResolveAuthor(ctx, client, &AuthorOptions{FloorID: "72"}, `該当なし`); err != ErrNotResolved {
+		t.Errorf("ResolveAuthor returned %v, expected %v", err, ErrNotResolved)
+	}
+}
@@ -0,0 +1,166 @@
+package dmm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Cache stores response bodies by key.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// SetCache is a client option for caching successful API responses for ttl.
+// Add it before SetRateLimit and SetMetrics, so that cache hits are neither rate limited nor counted.
+func SetCache(c Cache, ttl time.Duration) ClientOpt {
+	return UseMiddleware(CacheMiddleware(c, ttl))
+}
+
+// CacheMiddleware returns a Middleware answering GET calls from c, and storing
+// every successful response in c for ttl.
+// Decoded values are stored as JSON, and the raw body for values implementing io.Writer.
+// The key is the full request URL, so different credentials never share an entry.
+// Calls whose output can not be decoded into their value fail as they would without the cache.
+func CacheMiddleware(c Cache, ttl time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			if call.Request.Method != http.MethodGet || call.Value == nil {
+				return next(ctx, call)
+			}
+			if err := checkOutput(call.Request.URL, call.Value); err != nil {
+				return nil, err
+			}
+			v := call.Value
+			w, raw := v.(io.Writer)
+			key := call.Request.URL.String()
+			if raw {
+				key = "raw:" + key
+			}
+			if body, ok := c.Get(key); ok {
+				return cachedResponse(call, body)
+			}
+
+			if !raw {
+				resp, err := next(ctx, call)
+				if err != nil {
+					return resp, err
+				}
+				if body, err := json.Marshal(v); err == nil {
+					c.Set(key, body, ttl)
+				}
+				return resp, nil
+			}
+
+			// tee the raw body, so that the values seen by inner middleware are unchanged
+			var buf bytes.Buffer
+			call.Value = io.MultiWriter(w, &buf)
+			resp, err := next(ctx, call)
+			call.Value = v
+			if err != nil {
+				return resp, err
+			}
+			c.Set(key, buf.Bytes(), ttl)
+			return resp, nil
+		}
+	}
+}
+
+// cachedResponse decodes a cached body into the value of call
+func cachedResponse(call *Call, body []byte) (*Response, error) {
+	if w, ok := call.Value.(io.Writer); ok {
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(body, call.Value); err != nil {
+		return nil, err
+	}
+	hr := &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {mediaType}},
+		Body:          ioutil.NopCloser(bytes.NewReader(nil)),
+		ContentLength: int64(len(body)),
+		Request:       call.Request,
+	}
+	return newResponse(hr, call.Value), nil
+}
+
+// MemoryCache is an in-memory Cache whose entries expire after their ttl.
+// The zero value is an empty cache ready to use. It is safe for concurrent use.
+type MemoryCache struct {
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+var _ Cache = &MemoryCache{}
+
+// NewMemoryCache returns an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{}
+}
+
+// Get returns the body stored for key, unless it has expired
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !m.now().Before(e.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e.body, true
+}
+
+// Set stores body for key until ttl has passed. Expired entries are dropped on the way.
+func (m *MemoryCache) Set(key string, body []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	for k, e := range m.entries {
+		if !now.Before(e.expires) {
+			delete(m.entries, k)
+		}
+	}
+	if m.entries == nil {
+		m.entries = map[string]cacheEntry{}
+	}
+	m.entries[key] = cacheEntry{body: append([]byte(nil), body...), expires: now.Add(ttl)}
+}
+
+// Len returns the number of entries, including expired ones not dropped yet
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+func (m *MemoryCache) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,171 @@
+package dmm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacheMiddleware(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, testItemsRequest)
+	})
+	cache := NewMemoryCache()
+	client.Use(CacheMiddleware(cache, time.Minute))
+
+	first, r1, err := client.Items.List(ctx, &ItemOptions{Hits: 1})
+	if err != nil {
+		t.Fatalf("Items.List returned error: %v", err)
+	}
+	second, r2, err := client.Items.List(ctx, &ItemOptions{Hits: 1})
+	if err != nil {
+		t.Fatalf("Items.List returned error from the cache: %v", err)
+	}
+	if requests != 1 || cache.Len() != 1 {
+		t.Errorf("sent %d requests with %d cache entries, expected 1 and 1", requests, cache.Len())
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("cached Items.List returned %+v, expected %+v", second, first)
+	}
+	if r2.StatusCode != http.StatusOK || r2.TotalCount != r1.TotalCount || r2.ResultCount != r1.ResultCount || !reflect.DeepEqual(r2.Parameters, r1.Parameters) {
+		t.Errorf("cached response is %+v, expected %+v", r2, r1)
+	}
+
+	if _, _, err := client.Items.List(ctx, &ItemOptions{Hits: 2}); err != nil {
+		t.Fatalf("Items.List returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("sent %d requests, expected other parameters not to be cached", requests)
+	}
+}
+
+func TestCacheMiddleware_inner(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testItemsRequest)
+	})
+	var counts []int
+	client.Use(CacheMiddleware(NewMemoryCache(), time.Minute), func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			resp, err := next(ctx, call)
+			if resp != nil {
+				counts = append(counts, resp.ResultCount)
+			}
+			return resp, err
+		}
+	})
+
+	is, _, err := client.Items.List(ctx, &ItemOptions{Hits: 1})
+	if err != nil {
+		t.Fatalf("Items.List returned error: %v", err)
+	}
+	if len(counts) != 1 || counts[0] != len(is) {
+		t.Errorf("inner middleware saw result counts %v, expected [%d]", counts, len(is))
+	}
+}
+
+func TestCacheMiddleware_raw(t *testing.T) {
+	setup()
+	defer teardown()
+
+	const body = `<?xml version="1.0" encoding="utf-8"?><response></response>`
+	requests := 0
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, body)
+	})
+	client.Use(CacheMiddleware(NewMemoryCache(), time.Minute))
+
+	for i := 0; i < 2; i++ {
+		req, _ := client.NewRequest(http.MethodGet, itemBasePath+"?output=xml", nil)
+		var buf bytes.Buffer
+		if _, err := client.Do(ctx, req, &buf); err != nil {
+			t.Fatalf("Client.Do returned error: %v", err)
+		}
+		if buf.String() != body {
+			t.Errorf("Client.Do wrote %q, expected %q", buf.String(), body)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("sent %d requests, expected the raw body to be cached", requests)
+	}
+}
+
+func TestCacheMiddleware_output(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><response></response>`)
+	})
+	client.Use(CacheMiddleware(NewMemoryCache(), time.Minute))
+
+	_, _, err := client.Items.List(ctx, &ItemOptions{Output: OutputXML})
+	if _, ok := err.(*OutputError); !ok {
+		t.Errorf("Items.List returned %v, expected an *OutputError", err)
+	}
+	if requests != 0 {
+		t.Errorf("sent %d requests, expected none", requests)
+	}
+}
+
+func TestCacheMiddleware_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"result":{"status":400,"message":"BAD REQUEST"}}`)
+	})
+	cache := NewMemoryCache()
+	client.Use(CacheMiddleware(cache, time.Minute))
+
+	if _, _, err := client.Items.List(ctx, nil); err == nil {
+		t.Fatal("Items.List returned no error")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("cached %d failed responses", cache.Len())
+	}
+}
+
+func TestMemoryCache_expiry(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &MemoryCache{Now: func() time.Time { return now }}
+
+	c.Set("a", []byte("body"), time.Minute)
+	c.Set("b", []byte("body"), 0)
+	if b, ok := c.Get("a"); !ok || string(b) != "body" {
+		t.Errorf("Get returned %q, %v", b, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get returned an entry stored without ttl")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get returned an expired entry")
+	}
+}
+
+func TestMemoryCache_zero(t *testing.T) {
+	var c MemoryCache
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get of an empty cache returned an entry")
+	}
+	c.Set("a", []byte("body"), time.Minute)
+	if b, ok := c.Get("a"); !ok || string(b) != "body" {
+		t.Errorf("Get returned %q, %v", b, ok)
+	}
+}
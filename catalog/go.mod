@@ -0,0 +1,11 @@
+module github.com/usk81/go-dmm/catalog
+
+go 1.12
+
+replace github.com/usk81/go-dmm => ../
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.6
+	github.com/usk81/generic/v2 v2.2.1
+	github.com/usk81/go-dmm v0.1.0
+)
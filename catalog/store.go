@@ -0,0 +1,333 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/usk81/go-dmm"
+
+	// registers the "sqlite3" database/sql driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Kinds are the ItemInfo keys stored as related entities
+var Kinds = []string{
+	dmm.ItemInfoActress,
+	dmm.ItemInfoAuthor,
+	dmm.ItemInfoDirector,
+	dmm.ItemInfoGenre,
+	dmm.ItemInfoLabel,
+	dmm.ItemInfoMaker,
+	dmm.ItemInfoSeries,
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS items (
+	content_id     TEXT PRIMARY KEY,
+	product_id     TEXT NOT NULL,
+	service_code   TEXT NOT NULL,
+	floor_code     TEXT NOT NULL,
+	title          TEXT NOT NULL,
+	date           TEXT NOT NULL,
+	price          TEXT NOT NULL,
+	list_price     TEXT NOT NULL,
+	review_count   INTEGER NOT NULL,
+	review_average TEXT NOT NULL,
+	stock          TEXT NOT NULL,
+	data           TEXT NOT NULL,
+	synced_at      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS items_floor_date ON items (service_code, floor_code, date);
+
+CREATE TABLE IF NOT EXISTS entities (
+	kind TEXT NOT NULL,
+	id   TEXT NOT NULL,
+	name TEXT NOT NULL,
+	ruby TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (kind, id)
+);
+
+CREATE TABLE IF NOT EXISTS item_entities (
+	content_id TEXT NOT NULL REFERENCES items (content_id) ON DELETE CASCADE,
+	kind       TEXT NOT NULL,
+	entity_id  TEXT NOT NULL,
+	position   INTEGER NOT NULL,
+	PRIMARY KEY (content_id, kind, entity_id),
+	FOREIGN KEY (kind, entity_id) REFERENCES entities (kind, id)
+);
+CREATE INDEX IF NOT EXISTS item_entities_entity ON item_entities (kind, entity_id);
+
+CREATE TABLE IF NOT EXISTS checkpoints (
+	key          TEXT PRIMARY KEY,
+	window_start TEXT NOT NULL,
+	next_offset  INTEGER NOT NULL,
+	updated_at   TEXT NOT NULL
+);
+`
+
+// Store is a SQLite database holding items, their related entities and sync checkpoints
+type Store struct {
+	db *sql.DB
+}
+
+// Checkpoint is the position a sync of a floor has reached
+type Checkpoint struct {
+	// Key identifies the synced floor
+	Key string
+	// WindowStart is the start of the date window being synced
+	WindowStart time.Time
+	// Offset is the offset of the next page within the window
+	Offset int
+}
+
+// Entity is an actress, genre, maker, etc. an item is related to
+type Entity struct {
+	Kind string
+	ID   string
+	Name string
+	Ruby string
+}
+
+// Open opens the SQLite database at path, creating it and its schema if needed
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// a single connection serializes writers and keeps ":memory:" databases alive
+	db.SetMaxOpenConns(1)
+	s, err := NewStore(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewStore returns a Store on db, creating the schema if needed
+func NewStore(db *sql.DB) (*Store, error) {
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// DB returns the underlying database for custom queries
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SavePage upserts items with their related entities and saves cp in a single transaction,
+// so a killed sync never loses track of what it has stored.
+func (s *Store) SavePage(ctx context.Context, cp Checkpoint, items []dmm.Item) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, i := range items {
+		if err = upsertItem(ctx, tx, i, now); err != nil {
+			return err
+		}
+	}
+	return saveCheckpoint(ctx, tx, cp, now)
+}
+
+// SaveCheckpoint saves cp
+func (s *Store) SaveCheckpoint(ctx context.Context, cp Checkpoint) error {
+	return saveCheckpoint(ctx, s.db, cp, time.Now().UTC().Format(time.RFC3339))
+}
+
+// Checkpoint returns the checkpoint saved for key
+func (s *Store) Checkpoint(ctx context.Context, key string) (cp Checkpoint, ok bool, err error) {
+	var start string
+	err = s.db.QueryRowContext(ctx,
+		`SELECT window_start, next_offset FROM checkpoints WHERE key = ?`, key,
+	).Scan(&start, &cp.Offset)
+	if err == sql.ErrNoRows {
+		return cp, false, nil
+	}
+	if err != nil {
+		return cp, false, err
+	}
+	cp.Key = key
+	if cp.WindowStart, err = time.Parse(time.RFC3339, start); err != nil {
+		return cp, false, err
+	}
+	return cp, true, nil
+}
+
+// DeleteCheckpoint forgets the progress saved for key, so the next sync starts over
+func (s *Store) DeleteCheckpoint(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM checkpoints WHERE key = ?`, key)
+	return err
+}
+
+// Item returns the stored item with the content ID
+func (s *Store) Item(ctx context.Context, contentID string) (i dmm.Item, ok bool, err error) {
+	var data string
+	err = s.db.QueryRowContext(ctx, `SELECT data FROM items WHERE content_id = ?`, contentID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return i, false, nil
+	}
+	if err != nil {
+		return i, false, err
+	}
+	if err = json.Unmarshal([]byte(data), &i); err != nil {
+		return i, false, err
+	}
+	return i, true, nil
+}
+
+// CountItems returns the number of stored items
+func (s *Store) CountItems(ctx context.Context) (n int, err error) {
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM items`).Scan(&n)
+	return n, err
+}
+
+// Entities returns the entities of kind related to the item, in their original order
+func (s *Store) Entities(ctx context.Context, contentID, kind string) ([]Entity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT e.kind, e.id, e.name, e.ruby
+FROM item_entities ie JOIN entities e ON e.kind = ie.kind AND e.id = ie.entity_id
+WHERE ie.content_id = ? AND ie.kind = ?
+ORDER BY ie.position`, contentID, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var es []Entity
+	for rows.Next() {
+		var e Entity
+		if err := rows.Scan(&e.Kind, &e.ID, &e.Name, &e.Ruby); err != nil {
+			return nil, err
+		}
+		es = append(es, e)
+	}
+	return es, rows.Err()
+}
+
+// ContentIDs returns the content IDs of the items related to an entity, newest first
+func (s *Store) ContentIDs(ctx context.Context, kind, id string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT i.content_id
+FROM item_entities ie JOIN items i ON i.content_id = ie.content_id
+WHERE ie.kind = ? AND ie.entity_id = ?
+ORDER BY i.date DESC, i.content_id`, kind, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cids []string
+	for rows.Next() {
+		var cid string
+		if err := rows.Scan(&cid); err != nil {
+			return nil, err
+		}
+		cids = append(cids, cid)
+	}
+	return cids, rows.Err()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func upsertItem(ctx context.Context, tx *sql.Tx, i dmm.Item, now string) error {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO items (content_id, product_id, service_code, floor_code, title, date, price, list_price,
+	review_count, review_average, stock, data, synced_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (content_id) DO UPDATE SET
+	product_id = excluded.product_id,
+	service_code = excluded.service_code,
+	floor_code = excluded.floor_code,
+	title = excluded.title,
+	date = excluded.date,
+	price = excluded.price,
+	list_price = excluded.list_price,
+	review_count = excluded.review_count,
+	review_average = excluded.review_average,
+	stock = excluded.stock,
+	data = excluded.data,
+	synced_at = excluded.synced_at`,
+		i.ContentID, i.ProductID, i.ServiceCode, i.FloorCode, i.Title, i.Date, i.Prices.Price, i.Prices.ListPrice,
+		i.Review.Count, i.Review.Average, i.Stock, string(data), now)
+	if err != nil {
+		return err
+	}
+
+	// relations are replaced, so entities the item no longer belongs to are dropped
+	if _, err = tx.ExecContext(ctx, `DELETE FROM item_entities WHERE content_id = ?`, i.ContentID); err != nil {
+		return err
+	}
+	for _, kind := range Kinds {
+		rubies := readings(i.ItemInfo[kind])
+		for pos, c := range i.Components(kind) {
+			id := c.ID.String()
+			_, err = tx.ExecContext(ctx, `
+INSERT INTO entities (kind, id, name, ruby) VALUES (?, ?, ?, ?)
+ON CONFLICT (kind, id) DO UPDATE SET
+	name = excluded.name,
+	ruby = CASE WHEN excluded.ruby <> '' THEN excluded.ruby ELSE entities.ruby END`,
+				kind, id, c.Name, rubies[id])
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `
+INSERT INTO item_entities (content_id, kind, entity_id, position) VALUES (?, ?, ?, ?)
+ON CONFLICT DO NOTHING`, i.ContentID, kind, id, pos)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readings maps component IDs to the readings given by their "_ruby" entries
+func readings(cs []dmm.ItemComponent) map[string]string {
+	rs := map[string]string{}
+	for _, c := range cs {
+		if id := c.ID.String(); strings.HasSuffix(id, "_ruby") {
+			rs[strings.TrimSuffix(id, "_ruby")] = c.Name
+		}
+	}
+	return rs
+}
+
+func saveCheckpoint(ctx context.Context, e execer, cp Checkpoint, now string) error {
+	_, err := e.ExecContext(ctx, `
+INSERT INTO checkpoints (key, window_start, next_offset, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET
+	window_start = excluded.window_start,
+	next_offset = excluded.next_offset,
+	updated_at = excluded.updated_at`,
+		cp.Key, cp.WindowStart.Format(time.RFC3339), cp.Offset, now)
+	return err
+}
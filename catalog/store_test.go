@@ -0,0 +1,88 @@
+package catalog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+)
+
+func TestStore_SavePage(t *testing.T) {
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	item := dmm.Item{
+		ContentID: "juy00553",
+		Title:     "title",
+		Date:      "2018-07-25 10:00:00",
+		Prices:    dmm.Prices{Price: "2381", ListPrice: "3218"},
+		ItemInfo: map[string][]dmm.ItemComponent{
+			dmm.ItemInfoActress: {
+				{ID: generic.MustString(1046150), Name: "壇えみ"},
+				{ID: generic.MustString("1046150_ruby"), Name: "だんえみ"},
+				{ID: generic.MustString("1046150_classify"), Name: "av"},
+			},
+			dmm.ItemInfoMaker: {{ID: generic.MustString(2661), Name: "マドンナ"}},
+			dmm.ItemInfoLabel: {{ID: generic.MustString(2931), Name: "Madonna"}},
+		},
+	}
+	cp := Checkpoint{Key: "FANZA/digital/videoa", WindowStart: time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC), Offset: 101}
+	if err := store.SavePage(ctx, cp, []dmm.Item{item}); err != nil {
+		t.Fatalf("Store.SavePage returned error: %v", err)
+	}
+
+	got, ok, err := store.Item(ctx, "juy00553")
+	if err != nil || !ok {
+		t.Fatalf("Store.Item returned %v, %v", ok, err)
+	}
+	if got.Title != item.Title || !reflect.DeepEqual(got.Prices, item.Prices) {
+		t.Errorf("Store.Item returned %+v", got)
+	}
+
+	es, err := store.Entities(ctx, "juy00553", dmm.ItemInfoActress)
+	if err != nil {
+		t.Fatalf("Store.Entities returned error: %v", err)
+	}
+	expected := []Entity{{Kind: dmm.ItemInfoActress, ID: "1046150", Name: "壇えみ", Ruby: "だんえみ"}}
+	if !reflect.DeepEqual(es, expected) {
+		t.Errorf("Store.Entities returned %+v, expected %+v", es, expected)
+	}
+
+	// the item leaves the label and the reading is kept
+	item.Title = "new title"
+	delete(item.ItemInfo, dmm.ItemInfoLabel)
+	item.ItemInfo[dmm.ItemInfoActress] = item.ItemInfo[dmm.ItemInfoActress][:1]
+	if err := store.SavePage(ctx, cp, []dmm.Item{item}); err != nil {
+		t.Fatalf("Store.SavePage returned error: %v", err)
+	}
+	if got, _, _ := store.Item(ctx, "juy00553"); got.Title != "new title" {
+		t.Errorf("item was not updated: %+v", got)
+	}
+	if es, _ := store.Entities(ctx, "juy00553", dmm.ItemInfoLabel); len(es) != 0 {
+		t.Errorf("label relation was not removed: %+v", es)
+	}
+	if es, _ := store.Entities(ctx, "juy00553", dmm.ItemInfoActress); len(es) != 1 || es[0].Ruby != "だんえみ" {
+		t.Errorf("actress reading was lost: %+v", es)
+	}
+	if cids, _ := store.ContentIDs(ctx, dmm.ItemInfoMaker, "2661"); !reflect.DeepEqual(cids, []string{"juy00553"}) {
+		t.Errorf("Store.ContentIDs returned %v", cids)
+	}
+
+	got2, ok, err := store.Checkpoint(ctx, cp.Key)
+	if err != nil || !ok || !got2.WindowStart.Equal(cp.WindowStart) || got2.Offset != cp.Offset {
+		t.Errorf("Store.Checkpoint returned %+v, %v, %v", got2, ok, err)
+	}
+	if err := store.DeleteCheckpoint(ctx, cp.Key); err != nil {
+		t.Fatalf("Store.DeleteCheckpoint returned error: %v", err)
+	}
+	if _, ok, _ := store.Checkpoint(ctx, cp.Key); ok {
+		t.Error("checkpoint was not deleted")
+	}
+}
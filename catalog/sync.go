@@ -0,0 +1,135 @@
+// Package catalog mirrors the DMM item catalog into a local SQLite database.
+//
+// A Syncer pages through the items of a floor in date windows and stores them
+// together with their actresses, genres, makers, series, labels, directors and authors.
+// Progress is checkpointed after every page, so a killed sync resumes where it stopped,
+// and a later sync of the same floor only fetches the windows after the last one synced.
+//
+// Open uses the github.com/mattn/go-sqlite3 driver, so building the package requires cgo.
+//
+//	store, err := catalog.Open("dmm.db")
+//	...
+//	s := &catalog.Syncer{Client: dmm.NewClient(nil), Store: store, APIID: apiID, AffiliateID: affiliateID}
+//	err = s.Sync(ctx, catalog.Floor{Site: dmm.SiteAdult, Service: "digital", Floor: "videoa"}, from, time.Now())
+package catalog
+
+import (
+	"context"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+const (
+	// DefaultHits is the page size used when Syncer.Hits is 0
+	DefaultHits = 100
+	// DefaultWindow is the date window used when Syncer.Window is 0
+	DefaultWindow = 7 * 24 * time.Hour
+
+	dateFormat = "2006-01-02T15:04:05"
+)
+
+// Floor identifies a floor to sync
+type Floor struct {
+	Site    string
+	Service string
+	Floor   string
+}
+
+// Key returns the checkpoint key of the floor
+func (f Floor) Key() string {
+	return f.Site + "/" + f.Service + "/" + f.Floor
+}
+
+// Syncer mirrors floors of the item catalog into a Store
+type Syncer struct {
+	Client      *dmm.Client
+	Store       *Store
+	APIID       string
+	AffiliateID string
+
+	// Hits is the page size
+	Hits int
+	// Window is the length of the date ranges (gte_date/lte_date) items are fetched in
+	Window time.Duration
+}
+
+// Sync stores the items of the floor released in [from, to).
+// If a checkpoint of the floor lies within the range, the sync resumes from it.
+// The API compares dates in JST, so from and to should be in that location.
+func (s *Syncer) Sync(ctx context.Context, f Floor, from, to time.Time) error {
+	key := f.Key()
+	start, offset := from, 1
+	cp, ok, err := s.Store.Checkpoint(ctx, key)
+	if err != nil {
+		return err
+	}
+	if ok && cp.WindowStart.After(from) {
+		start, offset = cp.WindowStart, cp.Offset
+	}
+	if ok && cp.WindowStart.Equal(from) {
+		offset = cp.Offset
+	}
+
+	for start.Before(to) {
+		end := start.Add(s.window())
+		if end.After(to) {
+			end = to
+		}
+		if err := s.syncWindow(ctx, key, f, start, end, offset); err != nil {
+			return err
+		}
+		if err := s.Store.SaveCheckpoint(ctx, Checkpoint{Key: key, WindowStart: end, Offset: 1}); err != nil {
+			return err
+		}
+		start, offset = end, 1
+	}
+	return nil
+}
+
+// SyncFloors syncs every floor in turn
+func (s *Syncer) SyncFloors(ctx context.Context, fs []Floor, from, to time.Time) error {
+	for _, f := range fs {
+		if err := s.Sync(ctx, f, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) syncWindow(ctx context.Context, key string, f Floor, start, end time.Time, offset int) error {
+	opt := &dmm.ItemOptions{
+		APIID:       s.APIID,
+		AffiliateID: s.AffiliateID,
+		Site:        f.Site,
+		Service:     f.Service,
+		Floor:       f.Floor,
+		Sort:        "date",
+		GteDate:     start.Format(dateFormat),
+		LteDate:     end.Add(-time.Second).Format(dateFormat),
+		Hits:        s.hits(),
+		Offset:      offset,
+	}
+	return s.Client.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+		is, r, err := s.Client.Items.List(ctx, opt)
+		if err != nil {
+			return r, err
+		}
+		cp := Checkpoint{Key: key, WindowStart: start, Offset: opt.Offset + len(is)}
+		return r, s.Store.SavePage(ctx, cp, is)
+	})
+}
+
+func (s *Syncer) hits() int {
+	if s.Hits > 0 {
+		return s.Hits
+	}
+	return DefaultHits
+}
+
+func (s *Syncer) window() time.Duration {
+	if s.Window > 0 {
+		return s.Window
+	}
+	return DefaultWindow
+}
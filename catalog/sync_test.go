@@ -0,0 +1,137 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// testItems returns n items of videoa released one per day from 2020-01-01
+func testItems(n int) []dmm.Item {
+	is := make([]dmm.Item, n)
+	for i := range is {
+		is[i] = dmm.Item{
+			ContentID:   fmt.Sprintf("abc%05d", i+1),
+			ServiceCode: "digital",
+			FloorCode:   "videoa",
+			Title:       fmt.Sprintf("title %d", i+1),
+			Date:        time.Date(2020, 1, 1+i, 10, 0, 0, 0, jst).Format("2006-01-02 15:04:05"),
+			Prices:      dmm.Prices{Price: "980"},
+			ItemInfo: map[string][]dmm.ItemComponent{
+				dmm.ItemInfoActress: {
+					{ID: generic.MustString(100 + i%2), Name: fmt.Sprintf("actress %d", i%2)},
+					{ID: generic.MustString(fmt.Sprintf("%d_ruby", 100+i%2)), Name: "あい"},
+				},
+				dmm.ItemInfoGenre: {
+					{ID: generic.MustString(1), Name: "genre"},
+				},
+			},
+		}
+	}
+	return is
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = testItems(10)
+
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	floor := Floor{Site: dmm.SiteAdult, Service: "digital", Floor: "videoa"}
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, jst)
+	to := time.Date(2020, 1, 11, 0, 0, 0, 0, jst)
+
+	// the first run dies on its 4th page
+	s := &Syncer{Client: server.NewClient(dmm.UseMiddleware(dmmtest.FailAfter(3))), Store: store, Hits: 2, Window: 5 * 24 * time.Hour}
+	if err := s.Sync(ctx, floor, from, to); err == nil {
+		t.Fatal("Expected error to be returned")
+	}
+	if n, _ := store.CountItems(ctx); n != 5 {
+		t.Errorf("stored %d items before failing, expected 5", n)
+	}
+	cp, ok, err := store.Checkpoint(ctx, floor.Key())
+	if err != nil || !ok {
+		t.Fatalf("Checkpoint returned %v, %v", ok, err)
+	}
+	if !cp.WindowStart.Equal(time.Date(2020, 1, 6, 0, 0, 0, 0, jst)) || cp.Offset != 1 {
+		t.Errorf("Checkpoint = %+v", cp)
+	}
+
+	// the second run resumes from the checkpoint
+	before := len(server.Requests())
+	s.Client = server.NewClient()
+	if err := s.Sync(ctx, floor, from, to); err != nil {
+		t.Fatalf("Syncer.Sync returned error: %v", err)
+	}
+	if n, _ := store.CountItems(ctx); n != 10 {
+		t.Errorf("stored %d items, expected 10", n)
+	}
+	resumed := server.Requests()[before]
+	if !strings.Contains(resumed, "gte_date=2020-01-06T00%3A00%3A00") || !strings.Contains(resumed, "offset=1") {
+		t.Errorf("sync resumed with %s", resumed)
+	}
+	if n := len(server.Requests()) - before; n != 3 {
+		t.Errorf("resumed sync sent %d requests, expected 3", n)
+	}
+
+	// syncing the same range again fetches nothing
+	before = len(server.Requests())
+	if err := s.Sync(ctx, floor, from, to); err != nil {
+		t.Fatalf("Syncer.Sync returned error: %v", err)
+	}
+	if n := len(server.Requests()) - before; n != 0 {
+		t.Errorf("repeated sync sent %d requests, expected 0", n)
+	}
+}
+
+func TestSyncer_Sync_resumeWithinWindow(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = testItems(10)
+
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	floor := Floor{Site: dmm.SiteAdult, Service: "digital", Floor: "videoa"}
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, jst)
+	to := time.Date(2020, 1, 11, 0, 0, 0, 0, jst)
+
+	s := &Syncer{Client: server.NewClient(dmm.UseMiddleware(dmmtest.FailAfter(2))), Store: store, Hits: 2, Window: 10 * 24 * time.Hour}
+	if err := s.Sync(ctx, floor, from, to); err == nil {
+		t.Fatal("Expected error to be returned")
+	}
+	cp, _, _ := store.Checkpoint(ctx, floor.Key())
+	if !cp.WindowStart.Equal(from) || cp.Offset != 5 {
+		t.Errorf("Checkpoint = %+v", cp)
+	}
+
+	before := len(server.Requests())
+	s.Client = server.NewClient()
+	if err := s.Sync(ctx, floor, from, to); err != nil {
+		t.Fatalf("Syncer.Sync returned error: %v", err)
+	}
+	if resumed := server.Requests()[before]; !strings.Contains(resumed, "offset=5") {
+		t.Errorf("sync resumed with %s", resumed)
+	}
+	if n, _ := store.CountItems(ctx); n != 10 {
+		t.Errorf("stored %d items, expected 10", n)
+	}
+}
@@ -0,0 +1,147 @@
+// Command dmm-proxy serves read-only endpoints of the DMM Affiliate API over HTTP,
+// injecting the credentials server-side so front-ends never hold the API ID.
+//
+// Usage:
+//
+//	dmm-proxy [flags]
+//
+// The endpoints are /items, /actresses, /genres, /makers, /series, /authors and /floors,
+// taking the API parameters as query parameters, e.g.
+//
+//	curl 'localhost:8080/items?site=FANZA&service=digital&floor=videoa&hits=10'
+//
+// Responses are cached for --cache-ttl and upstream calls are limited to --rate per second.
+// Prometheus metrics are served at /metrics on --metrics-addr, apart from the proxy, if it is set.
+//
+// Credentials are read from the DMM_API_ID and DMM_AFFILIATE_ID environment variables.
+// DMM_BASE_URL overrides the API endpoint.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/proxy"
+)
+
+// shutdownTimeout is how long in-flight requests may take after a signal
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	if err := run(ctx, os.Args[1:], os.Getenv, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "dmm-proxy:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, getenv func(string) string, stderr io.Writer) error {
+	srv, msrv, err := newServer(args, getenv, stderr)
+	if err != nil {
+		return err
+	}
+	servers := []*http.Server{srv}
+	if msrv != nil {
+		servers = append(servers, msrv)
+	}
+
+	logger := log.New(stderr, "", log.LstdFlags)
+	errc := make(chan error, len(servers))
+	for _, s := range servers {
+		go func(s *http.Server) {
+			logger.Printf("listening on %s", s.Addr)
+			errc <- s.ListenAndServe()
+		}(s)
+	}
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+	}
+
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, s := range servers {
+		if serr := s.Shutdown(sctx); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// newServer parses the flags and environment into an http.Server serving the proxy,
+// and another one serving the metrics if --metrics-addr is set
+func newServer(args []string, getenv func(string) string, stderr io.Writer) (srv, msrv *http.Server, err error) {
+	fs := flag.NewFlagSet("dmm-proxy", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	metricsAddr := fs.String("metrics-addr", "", "address to serve the metrics on, none if empty")
+	ttl := fs.Duration("cache-ttl", 5*time.Minute, "how long API responses are cached, 0 to disable")
+	rate := fs.Float64("rate", 1, "upstream API calls per second, 0 for no limit")
+	burst := fs.Int("burst", 5, "upstream API calls allowed in a burst")
+	maxHits := fs.Int("max-hits", proxy.DefaultMaxHits, "largest hits parameter clients may request")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, nil, fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	apiID, affiliateID := getenv("DMM_API_ID"), getenv("DMM_AFFILIATE_ID")
+	if apiID == "" || affiliateID == "" {
+		return nil, nil, fmt.Errorf("credentials are missing; set DMM_API_ID and DMM_AFFILIATE_ID")
+	}
+
+	// middleware added first runs first: cache hits are neither rate limited nor counted as upstream requests
+	var opts []dmm.ClientOpt
+	if u := getenv("DMM_BASE_URL"); u != "" {
+		opts = append(opts, dmm.SetBaseURL(u))
+	}
+	if *ttl > 0 {
+		opts = append(opts, dmm.SetCache(dmm.NewMemoryCache(), *ttl))
+	}
+	if *rate > 0 {
+		opts = append(opts, dmm.SetRateLimit(dmm.NewRateLimiter(*rate, *burst)))
+	}
+	metrics := dmm.NewMetrics()
+	opts = append(opts, dmm.SetMetrics(metrics))
+	cli, err := dmm.New(nil, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := proxy.NewHandler(cli, apiID, affiliateID)
+	h.MaxHits = *maxHits
+	srv = &http.Server{
+		Addr:         *addr,
+		Handler:      h,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: time.Minute,
+	}
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics)
+		msrv = &http.Server{
+			Addr:         *metricsAddr,
+			Handler:      mux,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: time.Minute,
+		}
+	}
+	return srv, msrv, nil
+}
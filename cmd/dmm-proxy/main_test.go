@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+func TestNewServer(t *testing.T) {
+	api := dmmtest.NewServer()
+	defer api.Close()
+	api.Items = []dmm.Item{{ContentID: "abc00001", Title: "first"}}
+
+	env := map[string]string{"DMM_API_ID": "api-1234", "DMM_AFFILIATE_ID": "aff-990", "DMM_BASE_URL": api.URL}
+	srv, msrv, err := newServer([]string{"--addr", "127.0.0.1:0", "--metrics-addr", "127.0.0.1:0", "--max-hits", "10"}, func(k string) string { return env[k] }, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("newServer returned error: %v", err)
+	}
+	if srv.Addr != "127.0.0.1:0" || msrv == nil || msrv.Addr != "127.0.0.1:0" {
+		t.Errorf("newServer listens on %s and %+v", srv.Addr, msrv)
+	}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?hits=5", nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"content_id":"abc00001"`) {
+			t.Fatalf("GET /items returned %d:\n%s", w.Code, w.Body)
+		}
+	}
+	if n := api.RequestCount("ItemList"); n != 1 {
+		t.Errorf("sent %d requests, expected the second one to be cached", n)
+	}
+	if r := api.Requests()[0]; !strings.Contains(r, "api_id=api-1234") {
+		t.Errorf("requested %s without the API ID", r)
+	}
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?hits=11", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("GET /items?hits=11 returned %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics of the proxy returned %d, expected %d", w.Code, http.StatusNotFound)
+	}
+
+	w = httptest.NewRecorder()
+	msrv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `dmm_requests_total{endpoint="affiliate/v3/ItemList",code="200",result_status="200"} 1`+"\n") {
+		t.Errorf("GET /metrics returned:\n%s", w.Body)
+	}
+}
+
+func TestNewServer_noMetrics(t *testing.T) {
+	env := map[string]string{"DMM_API_ID": "api-1234", "DMM_AFFILIATE_ID": "aff-990"}
+	_, msrv, err := newServer(nil, func(k string) string { return env[k] }, ioutil.Discard)
+	if err != nil || msrv != nil {
+		t.Errorf("newServer without --metrics-addr returned %+v, %v", msrv, err)
+	}
+}
+
+func TestNewServer_errors(t *testing.T) {
+	tests := []struct {
+		args []string
+		env  map[string]string
+	}{
+		{nil, map[string]string{"DMM_API_ID": "api-1234"}},
+		{[]string{"--rate", "fast"}, map[string]string{"DMM_API_ID": "api-1234", "DMM_AFFILIATE_ID": "aff-990"}},
+		{[]string{"serve"}, map[string]string{"DMM_API_ID": "api-1234", "DMM_AFFILIATE_ID": "aff-990"}},
+	}
+	for _, tt := range tests {
+		env := tt.env
+		if _, _, err := newServer(tt.args, func(k string) string { return env[k] }, ioutil.Discard); err == nil {
+			t.Errorf("newServer(%v) with %v returned no error", tt.args, env)
+		}
+	}
+}
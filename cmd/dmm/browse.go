@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/usk81/go-dmm"
+)
+
+// browsePageHits is the page size used to list every entity of a floor
+const browsePageHits = 500
+
+var errQuit = errors.New("quit")
+
+// browseCommand walks floors and their genres, makers, series or authors interactively
+// and prints the ItemOptions selecting the chosen entity.
+type browseCommand struct{}
+
+// entry is a genre, maker, series or author
+type entry struct {
+	ID   string
+	Name string
+	Ruby string
+}
+
+// category is an entity type items can be narrowed down by
+type category struct {
+	article string
+	label   string
+	fetch   func(ctx context.Context, cli *dmm.Client, cfg config, floorID, initial string) ([]entry, error)
+}
+
+var categories = []category{
+	{
+		article: "genre",
+		label:   "genres",
+		fetch: func(ctx context.Context, cli *dmm.Client, cfg config, floorID, initial string) ([]entry, error) {
+			opt := &dmm.GenreOptions{APIID: cfg.APIID, AffiliateID: cfg.AffiliateID, FloorID: floorID, Initial: initial, Hits: browsePageHits, Offset: 1}
+			var es []entry
+			err := cli.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+				gs, r, err := cli.Genres.List(ctx, opt)
+				for _, g := range gs {
+					es = append(es, entry{g.GenreID, g.Name, g.Ruby})
+				}
+				return r, err
+			})
+			return es, err
+		},
+	},
+	{
+		article: "maker",
+		label:   "makers",
+		fetch: func(ctx context.Context, cli *dmm.Client, cfg config, floorID, initial string) ([]entry, error) {
+			opt := &dmm.MakerOptions{APIID: cfg.APIID, AffiliateID: cfg.AffiliateID, FloorID: floorID, Initial: initial, Hits: browsePageHits, Offset: 1}
+			var es []entry
+			err := cli.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+				ms, r, err := cli.Makers.List(ctx, opt)
+				for _, m := range ms {
+					es = append(es, entry{m.MakerID, m.Name, m.Ruby})
+				}
+				return r, err
+			})
+			return es, err
+		},
+	},
+	{
+		article: "series",
+		label:   "series",
+		fetch: func(ctx context.Context, cli *dmm.Client, cfg config, floorID, initial string) ([]entry, error) {
+			opt := &dmm.SeriesOptions{APIID: cfg.APIID, AffiliateID: cfg.AffiliateID, FloorID: floorID, Initial: initial, Hits: browsePageHits, Offset: 1}
+			var es []entry
+			err := cli.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+				ss, r, err := cli.Series.List(ctx, opt)
+				for _, s := range ss {
+					es = append(es, entry{s.SeriesID, s.Name, s.Ruby})
+				}
+				return r, err
+			})
+			return es, err
+		},
+	},
+	{
+		article: "author",
+		label:   "authors",
+		fetch: func(ctx context.Context, cli *dmm.Client, cfg config, floorID, initial string) ([]entry, error) {
+			opt := &dmm.AuthorOptions{APIID: cfg.APIID, AffiliateID: cfg.AffiliateID, FloorID: floorID, Initial: initial, Hits: browsePageHits, Offset: 1}
+			var es []entry
+			err := cli.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+				as, r, err := cli.Authors.List(ctx, opt)
+				for _, a := range as {
+					es = append(es, entry{a.AuthorID, a.Name, a.Ruby})
+				}
+				return r, err
+			})
+			return es, err
+		},
+	},
+}
+
+func (c *browseCommand) summary() string {
+	return "browse floors and their genres, makers, series or authors interactively"
+}
+
+func (c *browseCommand) run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, getenv func(string) string) error {
+	fs := flag.NewFlagSet("browse", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	configPath := fs.String("config", "", "path to the JSON config file holding the credentials")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath, getenv)
+	if err != nil {
+		return err
+	}
+	cli, err := cfg.client()
+	if err != nil {
+		return err
+	}
+
+	b := &browser{in: bufio.NewScanner(stdin), out: stdout}
+	if err = b.browse(ctx, cli, cfg); err == errQuit {
+		return nil
+	}
+	return err
+}
+
+// browser prompts on out and reads answers from in
+type browser struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func (b *browser) browse(ctx context.Context, cli *dmm.Client, cfg config) error {
+	ss, _, err := cli.Floors.List(ctx, &dmm.FloorOptions{APIID: cfg.APIID, AffiliateID: cfg.AffiliateID})
+	if err != nil {
+		return err
+	}
+	floors := flattenFloors(ss)
+	if len(floors) == 0 {
+		return errors.New("no floors found")
+	}
+	labels := make([]string, len(floors))
+	for i, f := range floors {
+		labels[i] = fmt.Sprintf("%s / %s / %s (floor %s: %s)", f.SiteName, f.ServiceName, f.Name, f.ID, f.Code)
+	}
+	i, err := b.choose("Floors", labels)
+	if err != nil {
+		return err
+	}
+	floor := floors[i]
+
+	labels = []string{"all items of the floor"}
+	for _, c := range categories {
+		labels = append(labels, c.label)
+	}
+	if i, err = b.choose("Narrow down by", labels); err != nil {
+		return err
+	}
+	opt := &dmm.ItemOptions{Site: floor.SiteCode, Service: floor.ServiceCode, Floor: floor.Code}
+	if i == 0 {
+		b.printQuery(opt)
+		return nil
+	}
+	cat := categories[i-1]
+
+	for {
+		initial, err := b.prompt("Initial character (hiragana, empty for all): ")
+		if err != nil {
+			return err
+		}
+		es, err := cat.fetch(ctx, cli, cfg, floor.ID, initial)
+		if err != nil {
+			return err
+		}
+		if len(es) == 0 {
+			fmt.Fprintf(b.out, "No %s found.\n", cat.label)
+			continue
+		}
+		labels = make([]string, len(es))
+		for i, e := range es {
+			labels[i] = fmt.Sprintf("%s (%s) [%s]", e.Name, e.Ruby, e.ID)
+		}
+		if i, err = b.choose(cat.label, labels); err != nil {
+			return err
+		}
+		opt.Article = cat.article
+		opt.ArticleID = es[i].ID
+		b.printQuery(opt)
+		return nil
+	}
+}
+
+// choose lists labels and returns the index of the chosen one
+func (b *browser) choose(title string, labels []string) (int, error) {
+	fmt.Fprintf(b.out, "%s:\n", title)
+	for i, l := range labels {
+		fmt.Fprintf(b.out, "%4d) %s\n", i+1, l)
+	}
+	for {
+		s, err := b.prompt(fmt.Sprintf("Choose 1-%d (q to quit): ", len(labels)))
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(s)
+		if err == nil && n >= 1 && n <= len(labels) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(b.out, "Invalid choice %q.\n", s)
+	}
+}
+
+// prompt reads a trimmed answer; q or the end of input quits
+func (b *browser) prompt(msg string) (string, error) {
+	fmt.Fprint(b.out, msg)
+	if !b.in.Scan() {
+		if err := b.in.Err(); err != nil {
+			return "", err
+		}
+		return "", errQuit
+	}
+	s := strings.TrimSpace(b.in.Text())
+	if s == "q" {
+		return "", errQuit
+	}
+	return s, nil
+}
+
+// printQuery prints opt as Go code and as a dmm items command
+func (b *browser) printQuery(opt *dmm.ItemOptions) {
+	fields := []struct{ name, flag, value string }{
+		{"Site", "site", opt.Site},
+		{"Service", "service", opt.Service},
+		{"Floor", "floor", opt.Floor},
+		{"Article", "article", opt.Article},
+		{"ArticleID", "article-id", opt.ArticleID},
+	}
+	var lit, cmd []string
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		lit = append(lit, fmt.Sprintf("%s: %q", f.name, f.value))
+		cmd = append(cmd, fmt.Sprintf("--%s %s", f.flag, f.value))
+	}
+	fmt.Fprintln(b.out)
+	fmt.Fprintf(b.out, "dmm.ItemOptions{%s}\n", strings.Join(lit, ", "))
+	fmt.Fprintf(b.out, "dmm items %s\n", strings.Join(cmd, " "))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRun_browse(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	// floor 1, genres, no genre starting with か, then あ and the second genre
+	stdin := strings.NewReader("1\n2\nか\nあ\n3\n2\n")
+	var out bytes.Buffer
+	if err := run(context.Background(), []string{"browse"}, stdin, &out, testEnv(server)); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+
+	for _, s := range []string{
+		"1) FANZA（アダルト） / 動画 / ビデオ (floor 43: videoa)",
+		"No genres found.",
+		"2) 愛 (あい) [1002]",
+		`Invalid choice "3".`,
+		`dmm.ItemOptions{Site: "FANZA", Service: "digital", Floor: "videoa", Article: "genre", ArticleID: "1002"}`,
+		"dmm items --site FANZA --service digital --floor videoa --article genre --article-id 1002",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output does not contain %q:\n%s", s, out.String())
+		}
+	}
+}
+
+func TestRun_browseFloorOnly(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := run(context.Background(), []string{"browse"}, strings.NewReader("1\n1\n"), &out, testEnv(server)); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	expected := "dmm items --site FANZA --service digital --floor videoa\n"
+	if !strings.HasSuffix(out.String(), expected) {
+		t.Errorf("output does not end with %q:\n%s", expected, out.String())
+	}
+}
+
+func TestRun_browseQuit(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := run(context.Background(), []string{"browse"}, strings.NewReader("q\n"), &out, testEnv(server)); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if strings.Contains(out.String(), "dmm.ItemOptions") {
+		t.Errorf("query printed after quitting:\n%s", out.String())
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"reflect"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/internal/params"
+)
+
+// defaultPageHits is the page size used by --all when --hits is not given
+const defaultPageHits = 100
+
+type subcommand interface {
+	summary() string
+	run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, getenv func(string) string) error
+}
+
+// listCommand queries one of the list endpoints
+type listCommand struct {
+	name        string
+	description string
+	paged       bool
+	newOptions  func() dmm.ListOptions
+	fetch       func(context.Context, *dmm.Client, dmm.ListOptions) ([]interface{}, *dmm.Response, error)
+	header      []string
+	row         func(interface{}) []string
+}
+
+var commands = map[string]subcommand{
+	"browse": &browseCommand{},
+	"items": &listCommand{
+		name:        "items",
+		description: "search products (ItemList API)",
+		paged:       true,
+		newOptions:  func() dmm.ListOptions { return &dmm.ItemOptions{} },
+		fetch: func(ctx context.Context, cli *dmm.Client, opt dmm.ListOptions) ([]interface{}, *dmm.Response, error) {
+			is, r, err := cli.Items.List(ctx, opt.(*dmm.ItemOptions))
+			vs := make([]interface{}, len(is))
+			for i := range is {
+				vs[i] = is[i]
+			}
+			return vs, r, err
+		},
+		header: []string{"CONTENT_ID", "DATE", "PRICE", "TITLE"},
+		row: func(v interface{}) []string {
+			i := v.(dmm.Item)
+			return []string{i.ContentID, i.Date, i.Prices.Price, i.Title}
+		},
+	},
+	"actresses": &listCommand{
+		name:        "actresses",
+		description: "search actresses (ActressSearch API)",
+		paged:       true,
+		newOptions:  func() dmm.ListOptions { return &dmm.ActressOptions{} },
+		fetch: func(ctx context.Context, cli *dmm.Client, opt dmm.ListOptions) ([]interface{}, *dmm.Response, error) {
+			as, r, err := cli.Actresses.List(ctx, opt.(*dmm.ActressOptions))
+			vs := make([]interface{}, len(as))
+			for i := range as {
+				vs[i] = as[i]
+			}
+			return vs, r, err
+		},
+		header: []string{"ID", "NAME", "RUBY", "BIRTHDAY"},
+		row: func(v interface{}) []string {
+			a := v.(dmm.Actress)
+			return []string{a.ID, a.Name, a.Ruby, a.Birthday}
+		},
+	},
+	"genres": &listCommand{
+		name:        "genres",
+		description: "search genres of a floor (GenreSearch API)",
+		paged:       true,
+		newOptions:  func() dmm.ListOptions { return &dmm.GenreOptions{} },
+		fetch: func(ctx context.Context, cli *dmm.Client, opt dmm.ListOptions) ([]interface{}, *dmm.Response, error) {
+			gs, r, err := cli.Genres.List(ctx, opt.(*dmm.GenreOptions))
+			vs := make([]interface{}, len(gs))
+			for i := range gs {
+				vs[i] = gs[i]
+			}
+			return vs, r, err
+		},
+		header: []string{"GENRE_ID", "NAME", "RUBY"},
+		row: func(v interface{}) []string {
+			g := v.(dmm.Genre)
+			return []string{g.GenreID, g.Name, g.Ruby}
+		},
+	},
+	"makers": &listCommand{
+		name:        "makers",
+		description: "search makers of a floor (MakerSearch API)",
+		paged:       true,
+		newOptions:  func() dmm.ListOptions { return &dmm.MakerOptions{} },
+		fetch: func(ctx context.Context, cli *dmm.Client, opt dmm.ListOptions) ([]interface{}, *dmm.Response, error) {
+			ms, r, err := cli.Makers.List(ctx, opt.(*dmm.MakerOptions))
+			vs := make([]interface{}, len(ms))
+			for i := range ms {
+				vs[i] = ms[i]
+			}
+			return vs, r, err
+		},
+		header: []string{"MAKER_ID", "NAME", "RUBY"},
+		row: func(v interface{}) []string {
+			m := v.(dmm.Maker)
+			return []string{m.MakerID, m.Name, m.Ruby}
+		},
+	},
+	"series": &listCommand{
+		name:        "series",
+		description: "search series of a floor (SeriesSearch API)",
+		paged:       true,
+		newOptions:  func() dmm.ListOptions { return &dmm.SeriesOptions{} },
+		fetch: func(ctx context.Context, cli *dmm.Client, opt dmm.ListOptions) ([]interface{}, *dmm.Response, error) {
+			ss, r, err := cli.Series.List(ctx, opt.(*dmm.SeriesOptions))
+			vs := make([]interface{}, len(ss))
+			for i := range ss {
+				vs[i] = ss[i]
+			}
+			return vs, r, err
+		},
+		header: []string{"SERIES_ID", "NAME", "RUBY"},
+		row: func(v interface{}) []string {
+			s := v.(dmm.Series)
+			return []string{s.SeriesID, s.Name, s.Ruby}
+		},
+	},
+	"authors": &listCommand{
+		name:        "authors",
+		description: "search authors of a floor (AuthorSearch API)",
+		paged:       true,
+		newOptions:  func() dmm.ListOptions { return &dmm.AuthorOptions{} },
+		fetch: func(ctx context.Context, cli *dmm.Client, opt dmm.ListOptions) ([]interface{}, *dmm.Response, error) {
+			as, r, err := cli.Authors.List(ctx, opt.(*dmm.AuthorOptions))
+			vs := make([]interface{}, len(as))
+			for i := range as {
+				vs[i] = as[i]
+			}
+			return vs, r, err
+		},
+		header: []string{"AUTHOR_ID", "NAME", "RUBY"},
+		row: func(v interface{}) []string {
+			a := v.(dmm.Author)
+			return []string{a.AuthorID, a.Name, a.Ruby}
+		},
+	},
+	"floors": &listCommand{
+		name:        "floors",
+		description: "list sites, services and floors (FloorList API)",
+		newOptions:  func() dmm.ListOptions { return &dmm.FloorOptions{} },
+		fetch: func(ctx context.Context, cli *dmm.Client, opt dmm.ListOptions) ([]interface{}, *dmm.Response, error) {
+			ss, r, err := cli.Floors.List(ctx, opt.(*dmm.FloorOptions))
+			var vs []interface{}
+			for _, f := range flattenFloors(ss) {
+				vs = append(vs, f)
+			}
+			return vs, r, err
+		},
+		header: []string{"SITE", "SERVICE", "FLOOR_ID", "FLOOR", "NAME"},
+		row: func(v interface{}) []string {
+			f := v.(dmm.Floor)
+			return []string{f.SiteCode, f.ServiceCode, f.ID, f.Code, f.Name}
+		},
+	},
+}
+
+func (c *listCommand) summary() string {
+	return c.description
+}
+
+func (c *listCommand) run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, getenv func(string) string) error {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	opt := c.newOptions()
+	bindOptions(fs, opt)
+	configPath := fs.String("config", "", "path to the JSON config file holding the credentials")
+	format := fs.String("format", formatTable, "output format: table, json or ndjson")
+	var all *bool
+	if c.paged {
+		all = fs.Bool("all", false, "fetch every page")
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath, getenv)
+	if err != nil {
+		return err
+	}
+	cli, err := cfg.client()
+	if err != nil {
+		return err
+	}
+	params.SetCredentials(opt, cfg.APIID, cfg.AffiliateID)
+
+	p, err := newPrinter(*format, stdout, c.header, c.row)
+	if err != nil {
+		return err
+	}
+	fetch := func(ctx context.Context) (*dmm.Response, error) {
+		vs, r, err := c.fetch(ctx, cli, opt)
+		for _, v := range vs {
+			if perr := p.Print(v); perr != nil {
+				return r, perr
+			}
+		}
+		return r, err
+	}
+
+	if all != nil && *all {
+		if opt.GetHits() == 0 {
+			setIntField(opt, "Hits", defaultPageHits)
+		}
+		if opt.GetOffset() == 0 {
+			setIntField(opt, "Offset", 1)
+		}
+		err = cli.Paginate(ctx, opt, fetch)
+	} else {
+		_, err = fetch(ctx)
+	}
+	if ferr := p.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// flattenFloors returns every floor of ss with its site and service filled in
+func flattenFloors(ss []dmm.Site) []dmm.Floor {
+	var fs []dmm.Floor
+	for _, s := range ss {
+		for _, sv := range s.Services {
+			for _, f := range sv.Floor {
+				f.SiteName, f.SiteCode = s.Name, s.Code
+				f.ServiceName, f.ServiceCode = sv.Name, sv.Code
+				fs = append(fs, f)
+			}
+		}
+	}
+	return fs
+}
+
+func setIntField(opt interface{}, name string, n int) {
+	if f := reflect.ValueOf(opt).Elem().FieldByName(name); f.IsValid() {
+		f.SetInt(int64(n))
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/usk81/go-dmm"
+)
+
+// config holds the credentials and the endpoint used by the command
+type config struct {
+	APIID       string `json:"api_id"`
+	AffiliateID string `json:"affiliate_id"`
+	BaseURL     string `json:"base_url,omitempty"`
+}
+
+// loadConfig reads the config file at path, if any, and overrides it with environment variables.
+// A missing file is only an error if the path was given explicitly.
+func loadConfig(path string, getenv func(string) string) (config, error) {
+	var c config
+	explicit := path != ""
+	if !explicit {
+		path = getenv("DMM_CONFIG")
+		explicit = path != ""
+	}
+	if !explicit {
+		if home := getenv("HOME"); home != "" {
+			path = filepath.Join(home, ".config", "dmm", "config.json")
+		}
+	}
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		switch {
+		case err == nil:
+			if err = json.Unmarshal(data, &c); err != nil {
+				return c, fmt.Errorf("config %s: %v", path, err)
+			}
+		case explicit || !os.IsNotExist(err):
+			return c, err
+		}
+	}
+
+	if v := getenv("DMM_API_ID"); v != "" {
+		c.APIID = v
+	}
+	if v := getenv("DMM_AFFILIATE_ID"); v != "" {
+		c.AffiliateID = v
+	}
+	if v := getenv("DMM_BASE_URL"); v != "" {
+		c.BaseURL = v
+	}
+	if c.APIID == "" || c.AffiliateID == "" {
+		return c, fmt.Errorf("credentials are missing; set DMM_API_ID and DMM_AFFILIATE_ID or write them to %s", path)
+	}
+	return c, nil
+}
+
+// client returns an API client for c
+func (c config) client() (*dmm.Client, error) {
+	var opts []dmm.ClientOpt
+	if c.BaseURL != "" {
+		opts = append(opts, dmm.SetBaseURL(c.BaseURL))
+	}
+	return dmm.New(nil, opts...)
+}
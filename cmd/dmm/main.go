@@ -0,0 +1,65 @@
+// Command dmm queries the DMM Affiliate API from the command line.
+//
+// Usage:
+//
+//	dmm <command> [flags]
+//
+// Commands are items, actresses, genres, makers, series, authors and floors.
+// Flags map onto the parameters of the corresponding API, e.g.
+//
+//	dmm items --site FANZA --service digital --floor videoa --keyword 巨乳 --hits 10
+//	dmm genres --floor-id 43 --all --format ndjson
+//
+// The browse command walks floors and their genres, makers, series or authors
+// interactively and prints the ItemOptions selecting the chosen one.
+//
+// Credentials are read from the DMM_API_ID and DMM_AFFILIATE_ID environment variables,
+// or from a JSON config file given by --config or DMM_CONFIG
+// (default: $HOME/.config/dmm/config.json):
+//
+//	{"api_id": "...", "affiliate_id": "..."}
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Getenv); err != nil {
+		fmt.Fprintln(os.Stderr, "dmm:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, getenv func(string) string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stdout)
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stdout)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd.run(ctx, args[1:], stdin, stdout, getenv)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: dmm <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", n, commands[n].summary())
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "dmm <command> -h" for the flags of a command.`)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/affiliate/v3/ItemList", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("api_id") != "test-api" || q.Get("affiliate_id") != "test-990" {
+			t.Errorf("credentials were not sent: %s", r.URL.RawQuery)
+		}
+		hits, _ := strconv.Atoi(q.Get("hits"))
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		if hits == 0 {
+			hits = 20
+		}
+		if offset == 0 {
+			offset = 1
+		}
+		const total = 3
+		var items []string
+		for i := offset; i < offset+hits && i <= total; i++ {
+			items = append(items, fmt.Sprintf(`{"content_id":"item%d","title":"title\t%d","prices":{"price":"%d00"}}`, i, i, i))
+		}
+		fmt.Fprintf(w, `{"request":{"parameters":{"hits":"%d","offset":"%d"}},"result":{"status":200,"result_count":%d,"total_count":%d,"first_position":%d,"items":[%s]}}`,
+			hits, offset, len(items), total, offset, strings.Join(items, ","))
+	})
+	mux.HandleFunc("/affiliate/v3/FloorList", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"request":{"parameters":{}},"result":{"site":[{"name":"FANZA（アダルト）","code":"FANZA","service":[{"name":"動画","code":"digital","floor":[{"id":"43","name":"ビデオ","code":"videoa"}]}]}]}}`)
+	})
+	mux.HandleFunc("/affiliate/v3/GenreSearch", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("floor_id") != "43" {
+			t.Errorf("floor_id = %s, expected 43", q.Get("floor_id"))
+		}
+		genres := `{"genre_id":"1001","name":"あいうえお","ruby":"あいうえお"},{"genre_id":"1002","name":"愛","ruby":"あい"}`
+		count := 2
+		if q.Get("initial") == "か" {
+			genres, count = "", 0
+		}
+		fmt.Fprintf(w, `{"request":{"parameters":{"hits":"500","offset":"1"}},"result":{"status":200,"result_count":%d,"total_count":%d,"first_position":1,"floor_id":"43","genre":[%s]}}`,
+			count, count, genres)
+	})
+	return httptest.NewServer(mux)
+}
+
+func testEnv(server *httptest.Server) func(string) string {
+	env := map[string]string{
+		"DMM_API_ID":       "test-api",
+		"DMM_AFFILIATE_ID": "test-990",
+		"DMM_BASE_URL":     server.URL,
+	}
+	return func(k string) string { return env[k] }
+}
+
+func TestRun_itemsAllNDJSON(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"items", "--site", "FANZA", "--hits", "2", "--all", "--format", "ndjson"}, nil, &out, testEnv(server))
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("printed %d lines, expected 3:\n%s", len(lines), out.String())
+	}
+	for i, l := range lines {
+		var v struct {
+			ContentID string `json:"content_id"`
+		}
+		if err := json.Unmarshal([]byte(l), &v); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		if expected := fmt.Sprintf("item%d", i+1); v.ContentID != expected {
+			t.Errorf("line %d content_id = %s, expected %s", i, v.ContentID, expected)
+		}
+	}
+}
+
+func TestRun_itemsJSON(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := run(context.Background(), []string{"items", "--format", "json"}, nil, &out, testEnv(server)); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	var vs []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &vs); err != nil {
+		t.Fatalf("output is not a JSON array: %v", err)
+	}
+	if len(vs) != 3 {
+		t.Errorf("printed %d items, expected 3", len(vs))
+	}
+}
+
+func TestRun_floorsTable(t *testing.T) {
+	server := testServer(t)
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := run(context.Background(), []string{"floors"}, nil, &out, testEnv(server)); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	expected := "SITE   SERVICE  FLOOR_ID  FLOOR   NAME\nFANZA  digital  43        videoa  ビデオ\n"
+	if out.String() != expected {
+		t.Errorf("printed\n%s\nexpected\n%s", out.String(), expected)
+	}
+}
+
+func TestRun_unknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := run(context.Background(), []string{"unknown"}, nil, &out, func(string) string { return "" }); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dmm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"api_id":"file-api","affiliate_id":"file-990"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"DMM_AFFILIATE_ID": "env-990"}
+	c, err := loadConfig(path, func(k string) string { return env[k] })
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if c.APIID != "file-api" || c.AffiliateID != "env-990" {
+		t.Errorf("loadConfig returned %+v", c)
+	}
+
+	if _, err := loadConfig(filepath.Join(dir, "missing.json"), func(string) string { return "" }); err == nil {
+		t.Error("Expected error for a missing config file")
+	}
+	if _, err := loadConfig("", func(string) string { return "" }); err == nil {
+		t.Error("Expected error for missing credentials")
+	}
+}
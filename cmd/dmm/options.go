@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/usk81/go-dmm/internal/params"
+)
+
+// bindOptions registers a flag for every API parameter of opt, a pointer to an options struct.
+// Flag names are the parameter names with hyphens, e.g. gte_date becomes --gte-date.
+func bindOptions(fs *flag.FlagSet, opt interface{}) {
+	v := reflect.ValueOf(opt).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := params.Name(f)
+		if name == "" || params.Reserved[name] {
+			continue
+		}
+		flagName := strings.Replace(name, "_", "-", -1)
+		usage := name + " parameter"
+		switch p := v.Field(i).Addr().Interface().(type) {
+		case *string:
+			fs.StringVar(p, flagName, *p, usage)
+		case *int:
+			fs.IntVar(p, flagName, *p, usage)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable  = "table"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// printer writes fetched entities in one of the output formats
+type printer interface {
+	Print(v interface{}) error
+	Flush() error
+}
+
+func newPrinter(format string, w io.Writer, header []string, row func(interface{}) []string) (printer, error) {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		return &tablePrinter{w: tw, row: row}, nil
+	case formatJSON:
+		return &jsonPrinter{w: w, vs: []interface{}{}}, nil
+	case formatNDJSON:
+		return &ndjsonPrinter{enc: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q; use %s, %s or %s", format, formatTable, formatJSON, formatNDJSON)
+}
+
+type tablePrinter struct {
+	w   *tabwriter.Writer
+	row func(interface{}) []string
+}
+
+func (p *tablePrinter) Print(v interface{}) error {
+	cells := p.row(v)
+	for i, c := range cells {
+		cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(c)
+	}
+	_, err := fmt.Fprintln(p.w, strings.Join(cells, "\t"))
+	return err
+}
+
+func (p *tablePrinter) Flush() error {
+	return p.w.Flush()
+}
+
+// jsonPrinter writes a single JSON array, so it holds every entity until Flush
+type jsonPrinter struct {
+	w  io.Writer
+	vs []interface{}
+}
+
+func (p *jsonPrinter) Print(v interface{}) error {
+	p.vs = append(p.vs, v)
+	return nil
+}
+
+func (p *jsonPrinter) Flush() error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p.vs)
+}
+
+type ndjsonPrinter struct {
+	enc *json.Encoder
+}
+
+func (p *ndjsonPrinter) Print(v interface{}) error {
+	return p.enc.Encode(v)
+}
+
+func (p *ndjsonPrinter) Flush() error {
+	return nil
+}
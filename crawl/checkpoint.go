@@ -0,0 +1,97 @@
+package crawl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+// Checkpoint is the progress of a crawl persisted after every page
+type Checkpoint struct {
+	// Options are the options of the next page to fetch, without credentials
+	Options dmm.ItemOptions `json:"options"`
+	// TotalCount is the total count observed by the crawl
+	TotalCount int `json:"total_count"`
+	// Fetched is the number of items fetched so far
+	Fetched int `json:"fetched"`
+	// Range is the release date range requested from CrawlWindows
+	Range *Window `json:"range,omitempty"`
+	// Windows are the date windows left to crawl by CrawlWindows, the current one first
+	Windows   []Window  `json:"windows,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoadCheckpoint reads the checkpoint at path.
+// ok is false if there is no checkpoint.
+func LoadCheckpoint(path string) (cp Checkpoint, ok bool, err error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	if err = json.Unmarshal(data, &cp); err != nil {
+		return Checkpoint{}, false, err
+	}
+	return cp, true, nil
+}
+
+// SaveCheckpoint writes the checkpoint to path.
+// The file is replaced atomically, so a crash never leaves a partial checkpoint behind.
+// Credentials are never written.
+func SaveCheckpoint(path string, cp Checkpoint) error {
+	cp.Options.APIID = ""
+	cp.Options.AffiliateID = ""
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// RemoveCheckpoint deletes the checkpoint at path, if any
+func RemoveCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// sameQuery reports whether a and b select the same result set.
+// Credentials, page position and output settings are ignored.
+func sameQuery(a, b dmm.ItemOptions) bool {
+	return query(a) == query(b)
+}
+
+// sameWindowQuery is sameQuery ignoring the dates set per window
+func sameWindowQuery(a, b dmm.ItemOptions) bool {
+	a.GteDate, a.LteDate = "", ""
+	b.GteDate, b.LteDate = "", ""
+	return sameQuery(a, b)
+}
+
+func query(o dmm.ItemOptions) dmm.ItemOptions {
+	o.APIID, o.AffiliateID = "", ""
+	o.Hits, o.Offset = 0, 0
+	o.Output, o.Callback = "", ""
+	return o
+}
@@ -0,0 +1,56 @@
+package crawl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestSaveCheckpoint(t *testing.T) {
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+
+	cp := Checkpoint{
+		Options:    dmm.ItemOptions{APIID: "id", AffiliateID: "aff-990", Site: dmm.SiteAdult, Floor: "videoa", Hits: 10, Offset: 21},
+		TotalCount: 100,
+		Fetched:    20,
+		UpdatedAt:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := SaveCheckpoint(path, cp); err != nil {
+		t.Fatalf("SaveCheckpoint returned error: %v", err)
+	}
+
+	got, ok, err := LoadCheckpoint(path)
+	if err != nil || !ok {
+		t.Fatalf("LoadCheckpoint returned %v, %v", ok, err)
+	}
+	expected := cp
+	expected.Options.APIID, expected.Options.AffiliateID = "", ""
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("LoadCheckpoint returned %+v, expected %+v", got, expected)
+	}
+
+	if err := RemoveCheckpoint(path); err != nil {
+		t.Fatalf("RemoveCheckpoint returned error: %v", err)
+	}
+	if _, ok, err := LoadCheckpoint(path); ok || err != nil {
+		t.Errorf("LoadCheckpoint after RemoveCheckpoint returned %v, %v", ok, err)
+	}
+	if err := RemoveCheckpoint(path); err != nil {
+		t.Errorf("RemoveCheckpoint of a missing file returned error: %v", err)
+	}
+}
+
+func TestSameQuery(t *testing.T) {
+	a := dmm.ItemOptions{APIID: "a", Site: dmm.SiteAdult, Floor: "videoa", Hits: 10, Offset: 1}
+	b := dmm.ItemOptions{APIID: "b", Site: dmm.SiteAdult, Floor: "videoa", Hits: 100, Offset: 501}
+	if !sameQuery(a, b) {
+		t.Errorf("sameQuery(%+v, %+v) returned false", a, b)
+	}
+	b.Keyword = "keyword"
+	if sameQuery(a, b) {
+		t.Errorf("sameQuery(%+v, %+v) returned true", a, b)
+	}
+}
@@ -0,0 +1,153 @@
+// Package crawl pages through long item lists with a checkpoint file,
+// so that a crawl stopped by an error or a signal resumes where it stopped.
+//
+// After every page the options of the next page and the observed total count are written to the checkpoint.
+// If the total count changes mid-crawl, the result set has drifted and offsets no longer point at the same items;
+// the crawl stops with a *DriftError unless Crawler.OnDrift accepts the change.
+//
+// The API refuses offsets beyond MaxOffset; CrawlWindows reaches every item of a floor
+// by splitting the release date range into windows small enough to page through.
+//
+//	c := &crawl.Crawler{Client: cli, Path: "videoa.checkpoint.json"}
+//	opt := &dmm.ItemOptions{APIID: apiID, AffiliateID: affiliateID, Site: dmm.SiteAdult, Floor: "videoa", Hits: 100, Offset: 1}
+//	err := c.Crawl(ctx, opt, func(ctx context.Context, is []dmm.Item) error {
+//		...
+//	})
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+// DefaultHits is the page size used when the options specify no hits
+const DefaultHits = 100
+
+// ErrCheckpointMismatch is returned when the checkpoint was written by a crawl with other filters
+var ErrCheckpointMismatch = errors.New("crawl: checkpoint was written for different options")
+
+// DriftError reports that the total count of the result set changed mid-crawl
+type DriftError struct {
+	// Offset is the offset of the page that observed the change
+	Offset int
+	Old    int
+	New    int
+}
+
+func (e *DriftError) Error() string {
+	return fmt.Sprintf("crawl: total count changed from %d to %d at offset %d", e.Old, e.New, e.Offset)
+}
+
+// ItemsFunc handles the items of a page.
+// The checkpoint is advanced only after it returns nil.
+type ItemsFunc func(ctx context.Context, is []dmm.Item) error
+
+// Crawler pages through items, checkpointing its progress to a file
+type Crawler struct {
+	Client *dmm.Client
+	// Path is the checkpoint file
+	Path string
+
+	// OnDrift is called when the total count changes mid-crawl.
+	// Returning nil accepts the new total count and continues the crawl; if OnDrift is nil, the crawl stops with the *DriftError.
+	// Either way the checkpoint keeps the last good offset.
+	OnDrift func(ctx context.Context, err *DriftError) error
+
+	// MaxOffset is the largest offset CrawlWindows may request, MaxOffset if 0
+	MaxOffset int
+
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+}
+
+// Crawl calls fn with the items of every page matching opt, starting from opt.Offset
+// or from the checkpoint if one exists for the same filters.
+// opt is advanced as pages are fetched. The checkpoint is removed when the crawl completes.
+func (c *Crawler) Crawl(ctx context.Context, opt *dmm.ItemOptions, fn ItemsFunc) error {
+	if opt.Hits == 0 {
+		opt.Hits = DefaultHits
+	}
+	if opt.Offset == 0 {
+		opt.Offset = 1
+	}
+
+	cp, ok, err := LoadCheckpoint(c.Path)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if len(cp.Windows) > 0 || !sameQuery(cp.Options, *opt) {
+			return ErrCheckpointMismatch
+		}
+		opt.Offset = cp.Options.Offset
+	}
+
+	err = c.Client.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+		is, r, err := c.Client.Items.List(ctx, opt)
+		if err != nil {
+			return r, err
+		}
+		return r, c.handle(ctx, opt, &cp, is, r, nil, fn)
+	})
+	if err != nil {
+		return err
+	}
+	return RemoveCheckpoint(c.Path)
+}
+
+// handle passes a fetched page to fn and advances the checkpoint past it.
+// Items already in seen are skipped, if seen is not nil.
+func (c *Crawler) handle(ctx context.Context, opt *dmm.ItemOptions, cp *Checkpoint, is []dmm.Item, r *dmm.Response, seen map[string]bool, fn ItemsFunc) error {
+	if cp.TotalCount != 0 && r.TotalCount != cp.TotalCount {
+		if err := c.drift(ctx, &DriftError{Offset: opt.Offset, Old: cp.TotalCount, New: r.TotalCount}); err != nil {
+			return err
+		}
+	}
+	n := len(is)
+	if seen != nil {
+		is = unseen(seen, is)
+	}
+	if len(is) > 0 {
+		if err := fn(ctx, is); err != nil {
+			return err
+		}
+	}
+
+	cp.Options = *opt
+	cp.Options.Offset = opt.Offset + n
+	cp.TotalCount = r.TotalCount
+	cp.Fetched += len(is)
+	cp.UpdatedAt = c.now()
+	return SaveCheckpoint(c.Path, *cp)
+}
+
+func (c *Crawler) drift(ctx context.Context, err *DriftError) error {
+	if c.OnDrift == nil {
+		return err
+	}
+	return c.OnDrift(ctx, err)
+}
+
+func (c *Crawler) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// unseen returns the items not in seen and adds them to it
+func unseen(seen map[string]bool, is []dmm.Item) []dmm.Item {
+	var r []dmm.Item
+	for _, i := range is {
+		if seen[i.ContentID] {
+			continue
+		}
+		seen[i.ContentID] = true
+		r = append(r, i)
+	}
+	return r
+}
@@ -0,0 +1,156 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+func testItems(n int) []dmm.Item {
+	is := make([]dmm.Item, n)
+	for i := range is {
+		is[i] = dmm.Item{ContentID: fmt.Sprintf("abc%05d", i+1), FloorCode: "videoa"}
+	}
+	return is
+}
+
+func testOptions() *dmm.ItemOptions {
+	return &dmm.ItemOptions{APIID: "id", AffiliateID: "aff-990", Site: dmm.SiteAdult, Floor: "videoa", Hits: 10, Offset: 1}
+}
+
+// tempCheckpoint returns a checkpoint path in a new temporary directory and a func removing it
+func tempCheckpoint(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "crawl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "checkpoint.json"), func() { os.RemoveAll(dir) }
+}
+
+func collect(ids *[]string) ItemsFunc {
+	return func(ctx context.Context, is []dmm.Item) error {
+		for _, i := range is {
+			*ids = append(*ids, i.ContentID)
+		}
+		return nil
+	}
+}
+
+func TestCrawler_Crawl(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = testItems(25)
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+
+	var ids []string
+	c := &Crawler{Client: server.NewClient(), Path: path}
+	if err := c.Crawl(context.Background(), testOptions(), collect(&ids)); err != nil {
+		t.Fatalf("Crawler.Crawl returned error: %v", err)
+	}
+	if len(ids) != 25 {
+		t.Errorf("Crawler.Crawl fetched %d items, expected 25", len(ids))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint was not removed: %v", err)
+	}
+}
+
+func TestCrawler_Crawl_resume(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = testItems(25)
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+
+	var ids []string
+	c := &Crawler{Client: server.NewClient(dmm.UseMiddleware(dmmtest.FailAfter(2))), Path: path}
+	if err := c.Crawl(context.Background(), testOptions(), collect(&ids)); err == nil {
+		t.Fatal("Crawler.Crawl returned no error")
+	}
+	cp, ok, err := LoadCheckpoint(path)
+	if err != nil || !ok {
+		t.Fatalf("LoadCheckpoint returned %v, %v", ok, err)
+	}
+	if cp.Options.Offset != 21 || cp.TotalCount != 25 || cp.Fetched != 20 {
+		t.Errorf("checkpoint is %+v, expected offset 21, total count 25 and 20 fetched", cp)
+	}
+	if cp.Options.APIID != "" || cp.Options.AffiliateID != "" {
+		t.Errorf("checkpoint contains credentials: %+v", cp.Options)
+	}
+
+	before := len(server.Requests())
+	c = &Crawler{Client: server.NewClient(), Path: path}
+	if err := c.Crawl(context.Background(), testOptions(), collect(&ids)); err != nil {
+		t.Fatalf("Crawler.Crawl returned error: %v", err)
+	}
+	if n := len(server.Requests()) - before; n != 1 {
+		t.Errorf("resumed crawl sent %d requests, expected 1", n)
+	}
+	if len(ids) != 25 || ids[24] != "abc00025" {
+		t.Errorf("Crawler.Crawl fetched %v", ids)
+	}
+}
+
+func TestCrawler_Crawl_drift(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = testItems(25)
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+
+	var ids []string
+	fn := func(ctx context.Context, is []dmm.Item) error {
+		if len(ids) == 0 {
+			server.Items = append(server.Items, dmm.Item{ContentID: "new00001", FloorCode: "videoa"})
+		}
+		return collect(&ids)(ctx, is)
+	}
+	c := &Crawler{Client: server.NewClient(), Path: path}
+	err := c.Crawl(context.Background(), testOptions(), fn)
+	derr, ok := err.(*DriftError)
+	if !ok {
+		t.Fatalf("Crawler.Crawl returned %v, expected a *DriftError", err)
+	}
+	if derr.Offset != 11 || derr.Old != 25 || derr.New != 26 {
+		t.Errorf("Crawler.Crawl returned %+v", derr)
+	}
+	cp, _, _ := LoadCheckpoint(path)
+	if cp.Options.Offset != 11 {
+		t.Errorf("checkpoint offset is %d, expected the last good offset 11", cp.Options.Offset)
+	}
+
+	var drifts []*DriftError
+	c.OnDrift = func(ctx context.Context, err *DriftError) error {
+		drifts = append(drifts, err)
+		return nil
+	}
+	if err := c.Crawl(context.Background(), testOptions(), collect(&ids)); err != nil {
+		t.Fatalf("Crawler.Crawl returned error: %v", err)
+	}
+	if len(drifts) != 1 {
+		t.Errorf("OnDrift was called %d times, expected 1", len(drifts))
+	}
+	if len(ids) != 26 {
+		t.Errorf("Crawler.Crawl fetched %d items, expected 26", len(ids))
+	}
+}
+
+func TestCrawler_Crawl_mismatch(t *testing.T) {
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+	if err := SaveCheckpoint(path, Checkpoint{Options: dmm.ItemOptions{Site: dmm.SiteAdult, Floor: "videoc", Offset: 11}}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Crawler{Client: dmm.NewClient(nil), Path: path}
+	if err := c.Crawl(context.Background(), testOptions(), collect(new([]string))); err != ErrCheckpointMismatch {
+		t.Errorf("Crawler.Crawl returned %v, expected %v", err, ErrCheckpointMismatch)
+	}
+}
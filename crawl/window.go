@@ -0,0 +1,136 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+// MaxOffset is the largest offset ItemList accepts
+const MaxOffset = 50000
+
+const dateFormat = "2006-01-02T15:04:05"
+
+// Window is a release date range [Start, End) crawled by CrawlWindows
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// DepthError reports a window of a second holding more items than the API can page through
+type DepthError struct {
+	Window     Window
+	TotalCount int
+	Depth      int
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("crawl: %d items released at %s exceed the reachable depth %d",
+		e.TotalCount, e.Window.Start.Format(dateFormat), e.Depth)
+}
+
+// CrawlWindows calls fn with every item matching opt released in [from, to), exactly once.
+//
+// The API refuses offsets beyond MaxOffset, so items are fetched in date windows (gte_date/lte_date),
+// starting with [from, to). A window whose total count exceeds the reachable depth is bisected
+// until every window can be paged through completely, and items are de-duplicated by ContentID.
+// The pending windows are part of the checkpoint, so a stopped crawl resumes in the window it stopped in.
+// Resuming with other options or another range than the checkpoint was written for returns ErrCheckpointMismatch.
+// The API compares dates in JST, so from and to should be in that location.
+func (c *Crawler) CrawlWindows(ctx context.Context, opt *dmm.ItemOptions, from, to time.Time, fn ItemsFunc) error {
+	if opt.Hits == 0 {
+		opt.Hits = DefaultHits
+	}
+	opt.Offset = 1
+
+	cp, ok, err := LoadCheckpoint(c.Path)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if len(cp.Windows) == 0 || !sameWindowQuery(cp.Options, *opt) || !cp.Range.equal(from, to) {
+			return ErrCheckpointMismatch
+		}
+		opt.Offset = cp.Options.Offset
+	} else {
+		cp.Range = &Window{Start: from, End: to}
+		cp.Windows = []Window{*cp.Range}
+	}
+
+	seen := map[string]bool{}
+	for len(cp.Windows) > 0 {
+		w := cp.Windows[0]
+		opt.GteDate = w.Start.Format(dateFormat)
+		opt.LteDate = w.End.Add(-time.Second).Format(dateFormat)
+
+		split, err := c.crawlWindow(ctx, opt, &cp, seen, fn)
+		if err != nil {
+			return err
+		}
+		if split {
+			a, b := w.bisect()
+			cp.Windows = append([]Window{a, b}, cp.Windows[1:]...)
+		} else {
+			cp.Windows = cp.Windows[1:]
+		}
+		opt.Offset = 1
+		cp.Options = *opt
+		cp.TotalCount = 0
+		cp.UpdatedAt = c.now()
+		if err := SaveCheckpoint(c.Path, cp); err != nil {
+			return err
+		}
+	}
+	return RemoveCheckpoint(c.Path)
+}
+
+// crawlWindow pages through the window set in opt.
+// It reports without fetching further pages that the window must be split
+// if its first page shows more items than are reachable.
+func (c *Crawler) crawlWindow(ctx context.Context, opt *dmm.ItemOptions, cp *Checkpoint, seen map[string]bool, fn ItemsFunc) (split bool, err error) {
+	err = c.Client.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+		is, r, err := c.Client.Items.List(ctx, opt)
+		if err != nil {
+			return r, err
+		}
+		if opt.Offset == 1 {
+			if d := c.depth(opt.Hits); r.TotalCount > d {
+				w := cp.Windows[0]
+				if w.End.Sub(w.Start) <= time.Second {
+					return r, &DepthError{Window: w, TotalCount: r.TotalCount, Depth: d}
+				}
+				split = true
+				return nil, nil
+			}
+		}
+		return r, c.handle(ctx, opt, cp, is, r, seen, fn)
+	})
+	return split, err
+}
+
+// depth returns the number of items reachable with hits per page,
+// i.e. up to the end of the last page whose offset is within the maximum
+func (c *Crawler) depth(hits int) int {
+	max := MaxOffset
+	if c.MaxOffset > 0 {
+		max = c.MaxOffset
+	}
+	last := 1 + (max-1)/hits*hits
+	return last + hits - 1
+}
+
+// equal reports whether w is [from, to). A nil w equals no range.
+func (w *Window) equal(from, to time.Time) bool {
+	return w != nil && w.Start.Equal(from) && w.End.Equal(to)
+}
+
+// bisect splits w at its middle second
+func (w Window) bisect() (Window, Window) {
+	mid := w.Start.Add(w.End.Sub(w.Start) / 2).Truncate(time.Second)
+	if !mid.After(w.Start) {
+		mid = w.Start.Add(time.Second)
+	}
+	return Window{Start: w.Start, End: mid}, Window{Start: mid, End: w.End}
+}
@@ -0,0 +1,166 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// datedItems returns n items, released perDay a day at the same second from 2020-01-01
+func datedItems(n, perDay int) []dmm.Item {
+	is := make([]dmm.Item, n)
+	for i := range is {
+		is[i] = dmm.Item{
+			ContentID: fmt.Sprintf("abc%05d", i+1),
+			FloorCode: "videoa",
+			Date:      time.Date(2020, 1, 1+i/perDay, 10, 0, 0, 0, jst).Format("2006-01-02 15:04:05"),
+		}
+	}
+	return is
+}
+
+func windowOptions() *dmm.ItemOptions {
+	return &dmm.ItemOptions{APIID: "api-1234", AffiliateID: "aff-990", Site: dmm.SiteAdult, Floor: "videoa", Hits: 5}
+}
+
+func TestCrawler_CrawlWindows(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = datedItems(60, 3)
+	server.MaxOffset = 10
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+
+	var ids []string
+	c := &Crawler{Client: server.NewClient(), Path: path, MaxOffset: 10}
+	from, to := time.Date(2020, 1, 1, 0, 0, 0, 0, jst), time.Date(2020, 2, 1, 0, 0, 0, 0, jst)
+	if err := c.CrawlWindows(context.Background(), windowOptions(), from, to, collect(&ids)); err != nil {
+		t.Fatalf("Crawler.CrawlWindows returned error: %v", err)
+	}
+
+	if len(ids) != 60 {
+		t.Fatalf("Crawler.CrawlWindows fetched %d items, expected 60", len(ids))
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		if expected := fmt.Sprintf("abc%05d", i+1); id != expected {
+			t.Fatalf("Crawler.CrawlWindows fetched %v at %d, expected %v", id, i, expected)
+		}
+	}
+}
+
+func TestCrawler_CrawlWindows_resume(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = datedItems(60, 3)
+	server.MaxOffset = 10
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+	from, to := time.Date(2020, 1, 1, 0, 0, 0, 0, jst), time.Date(2020, 2, 1, 0, 0, 0, 0, jst)
+
+	var ids []string
+	c := &Crawler{Client: server.NewClient(dmm.UseMiddleware(dmmtest.FailAfter(6))), Path: path, MaxOffset: 10}
+	if err := c.CrawlWindows(context.Background(), windowOptions(), from, to, collect(&ids)); err == nil {
+		t.Fatal("Crawler.CrawlWindows returned no error")
+	}
+	cp, ok, err := LoadCheckpoint(c.Path)
+	if err != nil || !ok || len(cp.Windows) == 0 {
+		t.Fatalf("LoadCheckpoint returned %+v, %v, %v", cp, ok, err)
+	}
+
+	c.Client = server.NewClient()
+	if err := c.CrawlWindows(context.Background(), windowOptions(), from, to, collect(&ids)); err != nil {
+		t.Fatalf("Crawler.CrawlWindows returned error: %v", err)
+	}
+	if len(ids) != 60 {
+		t.Errorf("Crawler.CrawlWindows fetched %d items, expected 60", len(ids))
+	}
+	if _, ok, err := LoadCheckpoint(c.Path); ok || err != nil {
+		t.Errorf("checkpoint was not removed: %v, %v", ok, err)
+	}
+}
+
+func TestCrawler_CrawlWindows_rangeMismatch(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = datedItems(60, 3)
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+	from, to := time.Date(2020, 1, 1, 0, 0, 0, 0, jst), time.Date(2020, 2, 1, 0, 0, 0, 0, jst)
+
+	c := &Crawler{Client: server.NewClient(dmm.UseMiddleware(dmmtest.FailAfter(2))), Path: path, MaxOffset: 10}
+	if err := c.CrawlWindows(context.Background(), windowOptions(), from, to, collect(new([]string))); err == nil {
+		t.Fatal("Crawler.CrawlWindows returned no error")
+	}
+
+	c.Client = server.NewClient()
+	n := server.RequestCount("ItemList")
+	err := c.CrawlWindows(context.Background(), windowOptions(), from, to.AddDate(0, 1, 0), collect(new([]string)))
+	if err != ErrCheckpointMismatch {
+		t.Errorf("Crawler.CrawlWindows returned %v, expected %v", err, ErrCheckpointMismatch)
+	}
+	if server.RequestCount("ItemList") != n {
+		t.Errorf("Crawler.CrawlWindows sent %d requests, expected none", server.RequestCount("ItemList")-n)
+	}
+}
+
+func TestCrawler_CrawlWindows_depth(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = datedItems(20, 20)
+	path, cleanup := tempCheckpoint(t)
+	defer cleanup()
+
+	c := &Crawler{Client: server.NewClient(), Path: path, MaxOffset: 10}
+	from, to := time.Date(2020, 1, 1, 0, 0, 0, 0, jst), time.Date(2020, 1, 2, 0, 0, 0, 0, jst)
+	err := c.CrawlWindows(context.Background(), windowOptions(), from, to, collect(new([]string)))
+	derr, ok := err.(*DepthError)
+	if !ok {
+		t.Fatalf("Crawler.CrawlWindows returned %v, expected a *DepthError", err)
+	}
+	if derr.TotalCount != 20 || derr.Depth != 10 || !derr.Window.Start.Equal(time.Date(2020, 1, 1, 10, 0, 0, 0, jst)) {
+		t.Errorf("Crawler.CrawlWindows returned %+v", derr)
+	}
+}
+
+func TestCrawler_depth(t *testing.T) {
+	cases := []struct {
+		max, hits, depth int
+	}{
+		{0, 100, 50000},
+		{0, 30, 50010},
+		{10, 5, 10},
+		{10, 3, 12},
+	}
+	for _, c := range cases {
+		cr := &Crawler{MaxOffset: c.max}
+		if d := cr.depth(c.hits); d != c.depth {
+			t.Errorf("Crawler.depth(%d) with MaxOffset %d returned %d, expected %d", c.hits, c.max, d, c.depth)
+		}
+	}
+}
+
+func TestWindow_bisect(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, jst)
+	cases := []struct {
+		w   Window
+		mid time.Time
+	}{
+		{Window{start, start.Add(48 * time.Hour)}, start.Add(24 * time.Hour)},
+		{Window{start, start.Add(3 * time.Second)}, start.Add(time.Second)},
+		{Window{start, start.Add(2 * time.Second)}, start.Add(time.Second)},
+	}
+	for _, c := range cases {
+		a, b := c.w.bisect()
+		if !a.Start.Equal(c.w.Start) || !a.End.Equal(c.mid) || !b.Start.Equal(c.mid) || !b.End.Equal(c.w.End) {
+			t.Errorf("Window.bisect of %+v returned %+v, %+v", c.w, a, b)
+		}
+	}
+}
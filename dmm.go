@@ -46,6 +46,12 @@ type Client struct {
 	// User agent for client
 	UserAgent string
 
+	// Middleware wrapping every API call, outermost first
+	middleware []Middleware
+
+	// Tracer used for spans around pagination
+	tracer Tracer
+
 	// Services used for communicating with the API
 	Actresses ActressesService
 	Authors   AuthorsService
@@ -104,6 +110,14 @@ func SetBaseURL(bu string) ClientOpt {
 	}
 }
 
+// UseMiddleware is a client option for appending middleware to the client's chain.
+func UseMiddleware(mw ...Middleware) ClientOpt {
+	return func(c *Client) error {
+		c.Use(mw...)
+		return nil
+	}
+}
+
 // SetUserAgent is a client option for setting the user agent.
 func SetUserAgent(ua string) ClientOpt {
 	return func(c *Client) error {
@@ -117,6 +131,7 @@ type Response struct {
 	*http.Response
 	Parameters ListOptions
 
+	ResultStatus  int
 	ResultCount   int
 	TotalCount    int
 	FirstPosition int
@@ -138,22 +153,27 @@ type ErrResult struct {
 	Errors  map[string]string `json:"errors,omitempty"`
 }
 
+// Error formats the failed request and API message, with credentials redacted.
 func (r *ErrorResponse) Error() string {
+	u := r.Response.Request.URL
+	var msg string
 	if r.Result.Errors != nil {
-		return fmt.Sprintf("%v %v: %d %s (%v)",
+		msg = fmt.Sprintf("%v %v: %d %s (%v)",
 			r.Response.Request.Method,
-			r.Response.Request.URL,
+			redactURL(u),
 			r.Response.StatusCode,
 			r.Result.Message,
 			r.Result.Errors,
 		)
+	} else {
+		msg = fmt.Sprintf("%v %v: %d %s",
+			r.Response.Request.Method,
+			redactURL(u),
+			r.Response.StatusCode,
+			r.Result.Message,
+		)
 	}
-	return fmt.Sprintf("%v %v: %d %s",
-		r.Response.Request.Method,
-		r.Response.Request.URL,
-		r.Response.StatusCode,
-		r.Result.Message,
-	)
+	return redactCredentials(msg, u)
 }
 
 // newResponse creates a new Response for the provided http.Response
@@ -213,7 +233,25 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 // Do sends an API request and returns the API response. The API response is JSON decoded and stored in the value
 // pointed to by v, or returned as an error if an API error has occurred. If v implements the io.Writer interface,
 // the raw response will be written to v, without attempting to decode it.
-func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (response *Response, err error) {
+func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	return c.do(ctx, &Call{Request: req, Value: v})
+}
+
+// do passes the call through the middleware chain.
+func (c *Client) do(ctx context.Context, call *Call) (*Response, error) {
+	return c.handler()(ctx, call)
+}
+
+// send is the innermost Handler of the middleware chain. It sends the request and decodes the response body.
+func (c *Client) send(ctx context.Context, call *Call) (response *Response, err error) {
+	req, v := call.Request, call.Value
+	if err = checkOutput(req.URL, v); err != nil {
+		return nil, err
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
 	var resp *http.Response
 	if resp, err = DoRequestWithClient(c.client, req); err != nil {
 		return nil, err
@@ -229,7 +267,7 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (resp
 	}
 
 	if v != nil {
-		err = json.NewDecoder(resp.Body).Decode(v)
+		err = decodeBody(resp.Body, req.URL, v)
 		if err != nil {
 			return nil, err
 		}
@@ -246,7 +284,7 @@ func DoRequestWithClient(client *http.Client, req *http.Request) (*http.Response
 
 // CheckResponse checks the API response for errors, and returns them if present. A response is considered an
 // error if it has a status code outside the 200 range. API error responses are expected to have either no response
-// body, or a JSON response body that maps to ErrorResponse. Any other response body will be silently ignored.
+// body, or a JSON (or JSONP) response body that maps to ErrorResponse. Any other response body will be silently ignored.
 func CheckResponse(r *http.Response) error {
 	if c := r.StatusCode; c >= 200 && c <= 299 {
 		return nil
@@ -255,6 +293,11 @@ func CheckResponse(r *http.Response) error {
 	errorResponse := &ErrorResponse{Response: r}
 	data, err := ioutil.ReadAll(r.Body)
 	if err == nil && len(data) > 0 {
+		if cb := r.Request.URL.Query().Get("callback"); cb != "" {
+			if data, err = stripJSONP(data, cb); err != nil {
+				return err
+			}
+		}
 		if err = json.Unmarshal(data, errorResponse); err != nil {
 			return err
 		}
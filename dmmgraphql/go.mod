@@ -0,0 +1,13 @@
+module github.com/usk81/go-dmm/dmmgraphql
+
+go 1.17
+
+replace github.com/usk81/go-dmm => ../
+
+require (
+	github.com/graph-gophers/graphql-go v1.5.0
+	github.com/usk81/generic/v2 v2.2.1
+	github.com/usk81/go-dmm v0.1.0
+)
+
+require github.com/google/go-querystring v1.0.0 // indirect
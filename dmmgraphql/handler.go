@@ -0,0 +1,71 @@
+package dmmgraphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// maxBodySize limits the size of a request body
+const maxBodySize = 1 << 20
+
+// Handler serves GraphQL queries over HTTP, as POST with a JSON body
+// or as GET with query, operationName and variables parameters.
+// Every request gets its own ActressLoader and ItemsLoader.
+type Handler struct {
+	Schema   *graphql.Schema
+	Resolver *Resolver
+}
+
+var _ http.Handler = &Handler{}
+
+// NewHandler returns a Handler executing queries on s, whose root resolver is r
+func NewHandler(s *graphql.Schema, r *Resolver) *Handler {
+	return &Handler{Schema: s, Resolver: r}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP executes the query of r
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				http.Error(w, "invalid variables: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "query is missing", http.StatusBadRequest)
+		return
+	}
+
+	ctx := h.Resolver.WithLoader(r.Context())
+	resp := h.Schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(data)
+}
@@ -0,0 +1,52 @@
+package dmmgraphql
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	r := &Resolver{Client: server.NewClient(), APIID: "api-1234", AffiliateID: "aff-990"}
+	h := NewHandler(MustParseSchema(r), r)
+
+	body := `{"query":"query($id: ID!) { actress(id: $id) { name } }","variables":{"id":"1"}}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	if w.Code != http.StatusOK || w.Body.String() != `{"data":{"actress":{"name":"Actress One"}}}` {
+		t.Errorf("POST returned %d %s", w.Code, w.Body)
+	}
+
+	q := url.Values{"query": {`query($id: ID!) { actress(id: $id) { name } }`}, "variables": {`{"id":"2"}`}}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+q.Encode(), nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"data":{"actress":{"name":"Actress Two"}}}` {
+		t.Errorf("GET returned %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?query=%7B+nothing+%7D", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"errors"`) {
+		t.Errorf("invalid query returned %d %s", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		method, body string
+		status       int
+	}{
+		{http.MethodPost, `{`, http.StatusBadRequest},
+		{http.MethodPost, `{}`, http.StatusBadRequest},
+		{http.MethodPut, `{}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, "/graphql", strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s %s returned %d, expected %d", tt.method, tt.body, w.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package dmmgraphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+const (
+	// DefaultBatchWait is how long a loader collects keys before fetching them
+	DefaultBatchWait = 2 * time.Millisecond
+	// DefaultConcurrency is the number of API calls a loader sends at once
+	DefaultConcurrency = 4
+)
+
+// ActressLoader loads actress profiles by ID, dataloader style.
+// Loads requested within the batch wait are collected, duplicate IDs are fetched once,
+// and every result is cached for the lifetime of the loader, usually one GraphQL request.
+// The ActressSearch API takes a single actress_id, so a batch sends one call per distinct ID,
+// at most Concurrency at a time.
+type ActressLoader struct {
+	client *dmm.Client
+	opt    dmm.ActressOptions
+	wait   time.Duration
+	// sem is shared by the batches, which may overlap
+	sem chan struct{}
+
+	mu    sync.Mutex
+	cache map[string]*actressCall
+	batch []string
+}
+
+type actressCall struct {
+	done    chan struct{}
+	actress dmm.Actress
+	found   bool
+	err     error
+}
+
+// NewActressLoader returns a loader searching with c and the credentials of opt.
+// DefaultBatchWait and DefaultConcurrency are used if wait or concurrency are 0.
+func NewActressLoader(c *dmm.Client, opt dmm.ActressOptions, wait time.Duration, concurrency int) *ActressLoader {
+	if wait <= 0 {
+		wait = DefaultBatchWait
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	return &ActressLoader{
+		client: c,
+		opt:    dmm.ActressOptions{APIID: opt.APIID, AffiliateID: opt.AffiliateID},
+		wait:   wait,
+		sem:    make(chan struct{}, concurrency),
+		cache:  map[string]*actressCall{},
+	}
+}
+
+// Load returns the actress with id. found is false if the API does not know the ID.
+func (l *ActressLoader) Load(ctx context.Context, id string) (a dmm.Actress, found bool, err error) {
+	call := l.call(id)
+	select {
+	case <-call.done:
+		return call.actress, call.found, call.err
+	case <-ctx.Done():
+		return dmm.Actress{}, false, ctx.Err()
+	}
+}
+
+// call returns the pending or finished call for id, scheduling a batch if needed
+func (l *ActressLoader) call(id string) *actressCall {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.cache[id]; ok {
+		return c
+	}
+	c := &actressCall{done: make(chan struct{})}
+	l.cache[id] = c
+	l.batch = append(l.batch, id)
+	if len(l.batch) == 1 {
+		time.AfterFunc(l.wait, l.dispatch)
+	}
+	return c
+}
+
+// dispatch fetches the collected batch. Calls are not tied to the context of a single
+// Load, since other loads may be waiting for the same ID.
+func (l *ActressLoader) dispatch() {
+	l.mu.Lock()
+	ids := l.batch
+	l.batch = nil
+	calls := make([]*actressCall, len(ids))
+	for i, id := range ids {
+		calls[i] = l.cache[id]
+	}
+	l.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		l.sem <- struct{}{}
+		go func(id string, c *actressCall) {
+			defer func() {
+				<-l.sem
+				wg.Done()
+			}()
+			opt := l.opt
+			opt.ActressID = id
+			as, _, err := l.client.Actresses.List(context.Background(), &opt)
+			if err != nil {
+				// forget the failure, so a later request may try again
+				l.mu.Lock()
+				delete(l.cache, id)
+				l.mu.Unlock()
+				c.err = err
+			}
+			for _, a := range as {
+				if a.ID == id {
+					c.actress, c.found = a, true
+					break
+				}
+			}
+			close(c.done)
+		}(ids[i], calls[i])
+	}
+	wg.Wait()
+}
+
+// ItemsLoader lists items, e.g. the other items of the genres of a page of items.
+// Lists with the same options are fetched once and cached for the lifetime of the loader,
+// usually one GraphQL request, and at most Concurrency lists are fetched at once.
+type ItemsLoader struct {
+	client *dmm.Client
+	sem    chan struct{}
+
+	mu    sync.Mutex
+	cache map[dmm.ItemOptions]*itemsCall
+}
+
+type itemsCall struct {
+	done  chan struct{}
+	items []dmm.Item
+	err   error
+}
+
+// NewItemsLoader returns a loader listing with c. DefaultConcurrency is used if concurrency is 0.
+func NewItemsLoader(c *dmm.Client, concurrency int) *ItemsLoader {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	return &ItemsLoader{client: c, sem: make(chan struct{}, concurrency), cache: map[dmm.ItemOptions]*itemsCall{}}
+}
+
+// Load returns the items listed with opt
+func (l *ItemsLoader) Load(ctx context.Context, opt dmm.ItemOptions) ([]dmm.Item, error) {
+	l.mu.Lock()
+	call, ok := l.cache[opt]
+	if !ok {
+		call = &itemsCall{done: make(chan struct{})}
+		l.cache[opt] = call
+		go l.fetch(opt, call)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.items, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch lists the items of opt. Like ActressLoader.dispatch, it is not tied to the context of a single Load.
+func (l *ItemsLoader) fetch(opt dmm.ItemOptions, call *itemsCall) {
+	l.sem <- struct{}{}
+	defer func() { <-l.sem }()
+	call.items, _, call.err = l.client.Items.List(context.Background(), &opt)
+	if call.err != nil {
+		// forget the failure, so a later request may try again
+		l.mu.Lock()
+		delete(l.cache, opt)
+		l.mu.Unlock()
+	}
+	close(call.done)
+}
@@ -0,0 +1,127 @@
+package dmmgraphql
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestActressLoader_Load(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	l := NewActressLoader(server.NewClient(), dmm.ActressOptions{APIID: "api-1234", AffiliateID: "aff-990", Keyword: "ignored"}, 10*time.Millisecond, 2)
+
+	ids := []string{"1", "2", "1", "9", "2", "1"}
+	found := make([]bool, len(ids))
+	names := make([]string, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			a, ok, err := l.Load(context.Background(), id)
+			if err != nil {
+				t.Errorf("Load(%s) returned error: %v", id, err)
+			}
+			names[i], found[i] = a.Name, ok
+		}(i, id)
+	}
+	wg.Wait()
+
+	for i, id := range ids {
+		if found[i] != (id != "9") {
+			t.Errorf("Load(%s) found %v", id, found[i])
+		}
+	}
+	if names[0] != "Actress One" || names[1] != "Actress Two" {
+		t.Errorf("Load returned names %v", names)
+	}
+	if n := server.RequestCount("ActressSearch"); n != 3 {
+		t.Errorf("sent %d requests, expected 3", n)
+	}
+
+	if _, _, err := l.Load(context.Background(), "2"); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if n := server.RequestCount("ActressSearch"); n != 3 {
+		t.Errorf("sent %d requests, expected the cached actress to be reused", n)
+	}
+	for _, r := range server.Requests() {
+		if strings.Contains(r, "keyword=") {
+			t.Errorf("requested %s, expected only credentials to be taken from the options", r)
+		}
+	}
+}
+
+func TestActressLoader_concurrency(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	var mu sync.Mutex
+	running, max := 0, 0
+	c := server.NewClient(dmm.UseMiddleware(func(next dmm.Handler) dmm.Handler {
+		return func(ctx context.Context, call *dmm.Call) (*dmm.Response, error) {
+			mu.Lock()
+			if running++; running > max {
+				max = running
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+			return next(ctx, call)
+		}
+	}))
+	l := NewActressLoader(c, dmm.ActressOptions{}, time.Millisecond, 1)
+
+	// the second batch is dispatched while the first is running
+	var wg sync.WaitGroup
+	for _, id := range []string{"1", "2"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if _, _, err := l.Load(context.Background(), id); err != nil {
+				t.Errorf("Load(%s) returned error: %v", id, err)
+			}
+		}(id)
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+	if max != 1 {
+		t.Errorf("ran %d requests at once, expected at most 1", max)
+	}
+}
+
+func TestActressLoader_error(t *testing.T) {
+	server := testServer()
+	l := NewActressLoader(server.NewClient(), dmm.ActressOptions{}, 0, 0)
+	server.Close()
+
+	if _, _, err := l.Load(context.Background(), "1"); err == nil {
+		t.Error("Load returned no error")
+	}
+	l.mu.Lock()
+	n := len(l.cache)
+	l.mu.Unlock()
+	if n != 0 {
+		t.Errorf("loader cached %d failed loads", n)
+	}
+}
+
+func TestActressLoader_canceled(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	l := NewActressLoader(server.NewClient(), dmm.ActressOptions{}, time.Hour, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := l.Load(ctx, "1"); err != context.Canceled {
+		t.Errorf("Load returned error %v, expected %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,384 @@
+package dmmgraphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/usk81/go-dmm"
+)
+
+// MaxFirst is the largest page size a query may request, the API's hits limit
+const MaxFirst = 100
+
+// Resolver is the root resolver of Schema.
+type Resolver struct {
+	Client      *dmm.Client
+	APIID       string
+	AffiliateID string
+
+	// BatchWait and Concurrency configure the loaders of every request
+	BatchWait   time.Duration
+	Concurrency int
+}
+
+type loaderKey struct{}
+
+// loaders are the loaders of a request
+type loaders struct {
+	actresses *ActressLoader
+	items     *ItemsLoader
+}
+
+// WithLoader returns a context carrying a new ActressLoader and ItemsLoader.
+// Loads are batched and cached among the resolvers executed with the context;
+// without it every actress and every list of component items is fetched on its own.
+func (r *Resolver) WithLoader(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loaderKey{}, r.newLoaders())
+}
+
+func (r *Resolver) newLoaders() *loaders {
+	return &loaders{
+		actresses: NewActressLoader(r.Client, dmm.ActressOptions{APIID: r.APIID, AffiliateID: r.AffiliateID}, r.BatchWait, r.Concurrency),
+		items:     NewItemsLoader(r.Client, r.Concurrency),
+	}
+}
+
+func (r *Resolver) loaders(ctx context.Context) *loaders {
+	if l, ok := ctx.Value(loaderKey{}).(*loaders); ok {
+		return l
+	}
+	return r.newLoaders()
+}
+
+// scope is the site, service and floor items were searched in
+type scope struct {
+	site, service, floor string
+}
+
+func (r *Resolver) itemOptions(s scope) dmm.ItemOptions {
+	return dmm.ItemOptions{APIID: r.APIID, AffiliateID: r.AffiliateID, Site: s.site, Service: s.service, Floor: s.floor}
+}
+
+func (r *Resolver) items(s scope, is []dmm.Item) []*itemResolver {
+	rs := make([]*itemResolver, len(is))
+	for i := range is {
+		rs[i] = &itemResolver{root: r, scope: s, item: is[i]}
+	}
+	return rs
+}
+
+// Item resolves Query.item
+func (r *Resolver) Item(ctx context.Context, args struct {
+	Site    string
+	Service *string
+	Floor   *string
+	Cid     graphql.ID
+}) (*itemResolver, error) {
+	s := scope{site: args.Site, service: str(args.Service), floor: str(args.Floor)}
+	opt := r.itemOptions(s)
+	opt.ContentID = string(args.Cid)
+	opt.Hits = 1
+	is, _, err := r.Client.Items.List(ctx, &opt)
+	if err != nil || len(is) == 0 {
+		return nil, err
+	}
+	return &itemResolver{root: r, scope: s, item: is[0]}, nil
+}
+
+// Items resolves Query.items
+func (r *Resolver) Items(ctx context.Context, args struct {
+	Site      string
+	Service   *string
+	Floor     *string
+	Keyword   *string
+	Sort      *string
+	Article   *string
+	ArticleID *graphql.ID
+	GteDate   *string
+	LteDate   *string
+	First     int32
+	Offset    int32
+}) (*itemListResolver, error) {
+	s := scope{site: args.Site, service: str(args.Service), floor: str(args.Floor)}
+	opt := r.itemOptions(s)
+	opt.Keyword = str(args.Keyword)
+	opt.Sort = str(args.Sort)
+	opt.Article = str(args.Article)
+	if args.ArticleID != nil {
+		opt.ArticleID = string(*args.ArticleID)
+	}
+	opt.GteDate = str(args.GteDate)
+	opt.LteDate = str(args.LteDate)
+	opt.Hits = hits(args.First, 20)
+	opt.Offset = offset(args.Offset)
+	is, resp, err := r.Client.Items.List(ctx, &opt)
+	if err != nil {
+		return nil, err
+	}
+	l := &itemListResolver{items: r.items(s, is)}
+	if resp != nil {
+		l.total = resp.TotalCount
+	}
+	return l, nil
+}
+
+// Actress resolves Query.actress
+func (r *Resolver) Actress(ctx context.Context, args struct{ ID graphql.ID }) (*actressResolver, error) {
+	a, found, err := r.loaders(ctx).actresses.Load(ctx, string(args.ID))
+	if err != nil || !found {
+		return nil, err
+	}
+	return &actressResolver{actress: a, profile: true}, nil
+}
+
+// Actresses resolves Query.actresses
+func (r *Resolver) Actresses(ctx context.Context, args struct {
+	Keyword *string
+	Initial *string
+	First   int32
+	Offset  int32
+}) (*actressListResolver, error) {
+	opt := dmm.ActressOptions{
+		APIID:       r.APIID,
+		AffiliateID: r.AffiliateID,
+		Keyword:     str(args.Keyword),
+		Initial:     str(args.Initial),
+		Hits:        hits(args.First, 20),
+		Offset:      offset(args.Offset),
+	}
+	as, resp, err := r.Client.Actresses.List(ctx, &opt)
+	if err != nil {
+		return nil, err
+	}
+	l := &actressListResolver{}
+	if resp != nil {
+		l.total = resp.TotalCount
+	}
+	for _, a := range as {
+		l.actresses = append(l.actresses, &actressResolver{actress: a, profile: true})
+	}
+	return l, nil
+}
+
+type itemListResolver struct {
+	total int
+	items []*itemResolver
+}
+
+func (l *itemListResolver) TotalCount() int32      { return int32(l.total) }
+func (l *itemListResolver) Items() []*itemResolver { return l.items }
+
+type actressListResolver struct {
+	total     int
+	actresses []*actressResolver
+}
+
+func (l *actressListResolver) TotalCount() int32             { return int32(l.total) }
+func (l *actressListResolver) Actresses() []*actressResolver { return l.actresses }
+
+type itemResolver struct {
+	root  *Resolver
+	scope scope
+	item  dmm.Item
+}
+
+func (i *itemResolver) ContentID() graphql.ID { return graphql.ID(i.item.ContentID) }
+func (i *itemResolver) ProductID() string     { return i.item.ProductID }
+func (i *itemResolver) Title() string         { return i.item.Title }
+func (i *itemResolver) Date() string          { return i.item.Date }
+func (i *itemResolver) ServiceCode() string   { return i.item.ServiceCode }
+func (i *itemResolver) FloorCode() string     { return i.item.FloorCode }
+func (i *itemResolver) URL() string           { return i.item.URL }
+func (i *itemResolver) AffiliateURL() string  { return i.item.AffiliateURL }
+func (i *itemResolver) Price() string         { return i.item.Prices.Price }
+func (i *itemResolver) ListPrice() string     { return i.item.Prices.ListPrice }
+func (i *itemResolver) MakerProduct() string  { return i.item.MakerProduct }
+func (i *itemResolver) Comment() string       { return i.item.Comment }
+
+func (i *itemResolver) ImageURL() *imageURLResolver {
+	return &imageURLResolver{i.item.ImageURL}
+}
+
+func (i *itemResolver) Review() *reviewResolver {
+	return &reviewResolver{i.item.Review}
+}
+
+func (i *itemResolver) Iteminfo() *itemInfoResolver {
+	// components of other items are searched in the item's own service and floor
+	s := i.scope
+	if i.item.ServiceCode != "" {
+		s.service = i.item.ServiceCode
+	}
+	if i.item.FloorCode != "" {
+		s.floor = i.item.FloorCode
+	}
+	return &itemInfoResolver{root: i.root, scope: s, item: i.item}
+}
+
+type imageURLResolver struct {
+	u dmm.ImageURL
+}
+
+func (u *imageURLResolver) List() string  { return u.u.List }
+func (u *imageURLResolver) Small() string { return u.u.Small }
+func (u *imageURLResolver) Large() string { return u.u.Large }
+
+type reviewResolver struct {
+	r dmm.Review
+}
+
+func (r *reviewResolver) Count() int32    { return int32(r.r.Count) }
+func (r *reviewResolver) Average() string { return r.r.Average }
+
+type itemInfoResolver struct {
+	root  *Resolver
+	scope scope
+	item  dmm.Item
+}
+
+// Actress loads the profiles of the item's actresses concurrently through the request's loader
+func (i *itemInfoResolver) Actress(ctx context.Context) ([]*actressResolver, error) {
+	cs := i.item.Components(dmm.ItemInfoActress)
+	rs := make([]*actressResolver, len(cs))
+	errs := make([]error, len(cs))
+	l := i.root.loaders(ctx).actresses
+	var wg sync.WaitGroup
+	for n, c := range cs {
+		wg.Add(1)
+		go func(n int, c dmm.ItemComponent) {
+			defer wg.Done()
+			a, found, err := l.Load(ctx, c.ID.String())
+			if !found {
+				a = dmm.Actress{ID: c.ID.String(), Name: c.Name}
+			}
+			rs[n], errs[n] = &actressResolver{actress: a, profile: found}, err
+		}(n, c)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
+
+func (i *itemInfoResolver) Genre() []*componentResolver {
+	return i.components(dmm.ItemInfoGenre)
+}
+
+func (i *itemInfoResolver) Maker() []*componentResolver {
+	return i.components(dmm.ItemInfoMaker)
+}
+
+func (i *itemInfoResolver) Label() []*componentResolver {
+	return i.components(dmm.ItemInfoLabel)
+}
+
+func (i *itemInfoResolver) Series() []*componentResolver {
+	return i.components(dmm.ItemInfoSeries)
+}
+
+func (i *itemInfoResolver) Director() []*componentResolver {
+	return i.components(dmm.ItemInfoDirector)
+}
+
+func (i *itemInfoResolver) Author() []*componentResolver {
+	return i.components(dmm.ItemInfoAuthor)
+}
+
+func (i *itemInfoResolver) components(article string) []*componentResolver {
+	cs := i.item.Components(article)
+	rs := make([]*componentResolver, len(cs))
+	for n, c := range cs {
+		rs[n] = &componentResolver{root: i.root, scope: i.scope, article: article, component: c, except: i.item.ContentID}
+	}
+	return rs
+}
+
+type componentResolver struct {
+	root      *Resolver
+	scope     scope
+	article   string
+	component dmm.ItemComponent
+	except    string
+}
+
+func (c *componentResolver) ID() graphql.ID { return graphql.ID(c.component.ID.String()) }
+func (c *componentResolver) Name() string   { return c.component.Name }
+
+// Items returns the newest items with the component, except the item it belongs to.
+// Components shared by the items of a request, e.g. their genres, are listed once through its ItemsLoader.
+func (c *componentResolver) Items(ctx context.Context, args struct{ First int32 }) ([]*itemResolver, error) {
+	n := hits(args.First, 10)
+	opt := c.root.itemOptions(c.scope)
+	opt.Article = c.article
+	opt.ArticleID = c.component.ID.String()
+	opt.Sort = "date"
+	opt.Hits = n + 1
+	if opt.Hits > MaxFirst {
+		opt.Hits = MaxFirst
+	}
+	is, err := c.root.loaders(ctx).items.Load(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	var others []dmm.Item
+	for _, it := range is {
+		if it.ContentID != c.except && len(others) < n {
+			others = append(others, it)
+		}
+	}
+	return c.root.items(c.scope, others), nil
+}
+
+type actressResolver struct {
+	actress dmm.Actress
+	profile bool
+}
+
+func (a *actressResolver) ID() graphql.ID      { return graphql.ID(a.actress.ID) }
+func (a *actressResolver) Name() string        { return a.actress.Name }
+func (a *actressResolver) Ruby() string        { return a.actress.Ruby }
+func (a *actressResolver) Bust() string        { return a.actress.Bust }
+func (a *actressResolver) Cup() string         { return a.actress.Cup }
+func (a *actressResolver) Waist() string       { return a.actress.Waist }
+func (a *actressResolver) Hip() string         { return a.actress.Hip }
+func (a *actressResolver) Height() string      { return a.actress.Height }
+func (a *actressResolver) Birthday() string    { return a.actress.Birthday }
+func (a *actressResolver) BloodType() string   { return a.actress.BloodType }
+func (a *actressResolver) Hobby() string       { return a.actress.Hobby }
+func (a *actressResolver) Prefectures() string { return a.actress.Prefectures }
+func (a *actressResolver) Profile() bool       { return a.profile }
+
+func (a *actressResolver) ImageURL() *imageURLResolver {
+	return &imageURLResolver{a.actress.ImageURL}
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// hits returns the page size argument, defaulting to def and capped to MaxFirst
+func hits(v int32, def int) int {
+	switch {
+	case v < 1:
+		return def
+	case v > MaxFirst:
+		return MaxFirst
+	}
+	return int(v)
+}
+
+// offset returns the 1-based offset argument
+func offset(v int32) int {
+	if v < 1 {
+		return 1
+	}
+	return int(v)
+}
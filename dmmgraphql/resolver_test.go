@@ -0,0 +1,179 @@
+package dmmgraphql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+func component(id, name string) dmm.ItemComponent {
+	return dmm.ItemComponent{ID: generic.MustString(id), Name: name}
+}
+
+func testServer() *dmmtest.Server {
+	s := dmmtest.NewServer()
+	s.Items = []dmm.Item{
+		{
+			ContentID: "ssis00001", ServiceCode: "digital", FloorCode: "videoa", Title: "first", Date: "2021-01-02 10:00:00",
+			ItemInfo: map[string][]dmm.ItemComponent{
+				dmm.ItemInfoActress: {component("1", "Actress One"), component("1_ruby", "actress one"), component("2", "Actress Two")},
+				dmm.ItemInfoGenre:   {component("5001", "Drama")},
+			},
+		},
+		{
+			ContentID: "ssis00002", ServiceCode: "digital", FloorCode: "videoa", Title: "second", Date: "2021-01-01 10:00:00",
+			ItemInfo: map[string][]dmm.ItemComponent{
+				dmm.ItemInfoActress: {component("1", "Actress One"), component("9", "Retired")},
+				dmm.ItemInfoGenre:   {component("5001", "Drama")},
+			},
+		},
+	}
+	s.Actresses = []dmm.Actress{
+		{ID: "1", Name: "Actress One", Ruby: "あくとれすわん", Bust: "85"},
+		{ID: "2", Name: "Actress Two", Ruby: "あくとれすつー", Bust: "90"},
+	}
+	return s
+}
+
+func exec(t *testing.T, r *Resolver, query string) map[string]interface{} {
+	resp := MustParseSchema(r).Exec(r.WithLoader(context.Background()), query, "", nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("query returned errors: %v", resp.Errors)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func marshal(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestResolver_items(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	r := &Resolver{Client: server.NewClient(), APIID: "api-1234", AffiliateID: "aff-990"}
+
+	data := exec(t, r, `{
+		items(site: "FANZA", service: "digital", floor: "videoa", sort: "date", first: 10) {
+			totalCount
+			items {
+				contentId
+				iteminfo {
+					actress { id name bust profile }
+					genre { id name }
+				}
+			}
+		}
+	}`)
+	expected := `{"items":{"items":[` +
+		`{"contentId":"ssis00001","iteminfo":{"actress":[{"bust":"85","id":"1","name":"Actress One","profile":true},{"bust":"90","id":"2","name":"Actress Two","profile":true}],"genre":[{"id":"5001","name":"Drama"}]}},` +
+		`{"contentId":"ssis00002","iteminfo":{"actress":[{"bust":"85","id":"1","name":"Actress One","profile":true},{"bust":"","id":"9","name":"Retired","profile":false}],"genre":[{"id":"5001","name":"Drama"}]}}` +
+		`],"totalCount":2}}`
+	if actual := marshal(data); actual != expected {
+		t.Errorf("query returned\n%s\nexpected\n%s", actual, expected)
+	}
+
+	if n := server.RequestCount("ActressSearch"); n != 3 {
+		t.Errorf("sent %d ActressSearch requests, expected one per distinct actress", n)
+	}
+	for _, req := range server.Requests() {
+		if !strings.Contains(req, "api_id=api-1234") {
+			t.Errorf("requested %s without credentials", req)
+		}
+	}
+}
+
+func TestResolver_item(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	r := &Resolver{Client: server.NewClient(), APIID: "api-1234", AffiliateID: "aff-990"}
+
+	data := exec(t, r, `{
+		item(site: "FANZA", cid: "ssis00002") {
+			title
+			iteminfo { genre { name items(first: 5) { contentId } } }
+		}
+		missing: item(site: "FANZA", cid: "none") { title }
+		actress(id: "2") { name ruby }
+		unknown: actress(id: "9") { name }
+	}`)
+	expected := `{"actress":{"name":"Actress Two","ruby":"あくとれすつー"},` +
+		`"item":{"iteminfo":{"genre":[{"items":[{"contentId":"ssis00001"}],"name":"Drama"}]},"title":"second"},` +
+		`"missing":null,"unknown":null}`
+	if actual := marshal(data); actual != expected {
+		t.Errorf("query returned\n%s\nexpected\n%s", actual, expected)
+	}
+
+	var related string
+	for _, req := range server.Requests() {
+		if strings.Contains(req, "article=genre") {
+			related = req
+		}
+	}
+	for _, p := range []string{"article_id=5001", "service=digital", "floor=videoa", "sort=date", "hits=6"} {
+		if !strings.Contains(related, p) {
+			t.Errorf("related items requested %q, expected %s", related, p)
+		}
+	}
+}
+
+func TestResolver_actresses(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	r := &Resolver{Client: server.NewClient(), APIID: "api-1234", AffiliateID: "aff-990"}
+
+	data := exec(t, r, `{ actresses(keyword: "Two", first: 500) { totalCount actresses { id } } }`)
+	if actual, expected := marshal(data), `{"actresses":{"actresses":[{"id":"2"}],"totalCount":1}}`; actual != expected {
+		t.Errorf("query returned %s, expected %s", actual, expected)
+	}
+	if req := server.Requests()[0]; !strings.Contains(req, "hits=100") {
+		t.Errorf("requested %s, expected hits to be capped", req)
+	}
+}
+
+func TestResolver_componentItems(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	r := &Resolver{Client: server.NewClient(), APIID: "api-1234", AffiliateID: "aff-990"}
+
+	data := exec(t, r, `{
+		items(site: "FANZA", service: "digital", floor: "videoa") {
+			items { contentId iteminfo { genre { items(first: 5) { contentId } } } }
+		}
+	}`)
+	expected := `{"items":{"items":[` +
+		`{"contentId":"ssis00001","iteminfo":{"genre":[{"items":[{"contentId":"ssis00002"}]}]}},` +
+		`{"contentId":"ssis00002","iteminfo":{"genre":[{"items":[{"contentId":"ssis00001"}]}]}}` +
+		`]}}`
+	if actual := marshal(data); actual != expected {
+		t.Errorf("query returned\n%s\nexpected\n%s", actual, expected)
+	}
+	if n := server.RequestCount("ItemList"); n != 2 {
+		t.Errorf("sent %d ItemList requests, expected the shared genre to be listed once", n)
+	}
+}
+
+func TestParseSchema_maxDepth(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	r := &Resolver{Client: server.NewClient(), APIID: "api-1234", AffiliateID: "aff-990"}
+
+	resp := MustParseSchema(r).Exec(r.WithLoader(context.Background()), `{
+		items(site: "FANZA") { items { iteminfo { genre { items { iteminfo { genre { name } } } } } } }
+	}`, "", nil)
+	if len(resp.Errors) == 0 {
+		t.Error("query deeper than MaxQueryDepth returned no errors")
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("query deeper than MaxQueryDepth sent %d requests", n)
+	}
+}
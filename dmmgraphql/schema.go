@@ -0,0 +1,136 @@
+// Package dmmgraphql serves the DMM Affiliate API as a GraphQL schema.
+//
+// Items expose their iteminfo, where actresses resolve to full profiles from the
+// ActressSearch API. Profiles are loaded through a per-request ActressLoader,
+// so a page of items costs one call per distinct actress rather than one per item.
+//
+//	r := &dmmgraphql.Resolver{Client: cli, APIID: apiID, AffiliateID: affiliateID}
+//	http.Handle("/graphql", dmmgraphql.NewHandler(dmmgraphql.MustParseSchema(r), r))
+//
+// A query fetching an item with its actresses and genres:
+//
+//	{
+//	  item(site: "FANZA", service: "digital", floor: "videoa", cid: "ssis00001") {
+//	    title
+//	    iteminfo {
+//	      actress { name bust height imageUrl { large } }
+//	      genre { id name }
+//	    }
+//	  }
+//	}
+package dmmgraphql
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Schema is the GraphQL schema served by Resolver
+const Schema = `
+schema {
+	query: Query
+}
+
+type Query {
+	# item returns the item with the content ID, or null
+	item(site: String!, service: String, floor: String, cid: ID!): Item
+	items(site: String!, service: String, floor: String, keyword: String, sort: String, article: String, articleId: ID, gteDate: String, lteDate: String, first: Int = 20, offset: Int = 1): ItemList!
+	# actress returns the actress with the ID, or null
+	actress(id: ID!): Actress
+	actresses(keyword: String, initial: String, first: Int = 20, offset: Int = 1): ActressList!
+}
+
+type ItemList {
+	totalCount: Int!
+	items: [Item!]!
+}
+
+type ActressList {
+	totalCount: Int!
+	actresses: [Actress!]!
+}
+
+type Item {
+	contentId: ID!
+	productId: String!
+	title: String!
+	date: String!
+	serviceCode: String!
+	floorCode: String!
+	url: String!
+	affiliateUrl: String!
+	imageUrl: ImageURL!
+	price: String!
+	listPrice: String!
+	review: Review!
+	makerProduct: String!
+	comment: String!
+	iteminfo: ItemInfo!
+}
+
+type ImageURL {
+	list: String!
+	small: String!
+	large: String!
+}
+
+type Review {
+	count: Int!
+	average: String!
+}
+
+type ItemInfo {
+	actress: [Actress!]!
+	genre: [ItemComponent!]!
+	maker: [ItemComponent!]!
+	label: [ItemComponent!]!
+	series: [ItemComponent!]!
+	director: [ItemComponent!]!
+	author: [ItemComponent!]!
+}
+
+# ItemComponent is a genre, maker, label, series, director or author of an item
+type ItemComponent {
+	id: ID!
+	name: String!
+	# items returns other items of the same site, service and floor with this component
+	items(first: Int = 10): [Item!]!
+}
+
+type Actress {
+	id: ID!
+	name: String!
+	ruby: String!
+	bust: String!
+	cup: String!
+	waist: String!
+	hip: String!
+	height: String!
+	birthday: String!
+	bloodType: String!
+	hobby: String!
+	prefectures: String!
+	imageUrl: ImageURL!
+	# profile is false if the ActressSearch API does not know the actress and only id and name are set
+	profile: Boolean!
+}
+`
+
+// MaxQueryDepth is the deepest query a parsed schema executes.
+// It allows the items of a component of an item of a list, but not the components of those.
+const MaxQueryDepth = 7
+
+// ParseSchema parses Schema with r as the root resolver.
+// Queries nested deeper than MaxQueryDepth are rejected, since every level of component items
+// fans out into more API calls. opts may override the limit.
+func ParseSchema(r *Resolver, opts ...graphql.SchemaOpt) (*graphql.Schema, error) {
+	return graphql.ParseSchema(Schema, r, append([]graphql.SchemaOpt{graphql.MaxDepth(MaxQueryDepth)}, opts...)...)
+}
+
+// MustParseSchema is like ParseSchema but panics on errors
+func MustParseSchema(r *Resolver, opts ...graphql.SchemaOpt) *graphql.Schema {
+	s, err := ParseSchema(r, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
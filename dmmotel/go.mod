@@ -0,0 +1,21 @@
+module github.com/usk81/go-dmm/dmmotel
+
+go 1.20
+
+replace github.com/usk81/go-dmm => ../
+
+require (
+	github.com/usk81/go-dmm v0.1.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/go-querystring v1.0.0 // indirect
+	github.com/usk81/generic/v2 v2.2.1 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
@@ -0,0 +1,71 @@
+// Package dmmotel adapts OpenTelemetry tracers to the dmm.Tracer interface.
+//
+// e.g.
+//
+//	cli, err := dmm.New(nil, dmm.SetTracer(dmmotel.NewTracer(otel.Tracer("dmm"))))
+package dmmotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/usk81/go-dmm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is a dmm.Tracer backed by an OpenTelemetry tracer
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var _ dmm.Tracer = &Tracer{}
+
+// NewTracer returns a dmm.Tracer starting client spans on t
+func NewTracer(t trace.Tracer) *Tracer {
+	return &Tracer{tracer: t}
+}
+
+// Start starts a client span
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, dmm.Span) {
+	ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, &span{span: s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s *span) SetAttributes(attrs ...dmm.Attribute) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		kvs = append(kvs, keyValue(a))
+	}
+	s.span.SetAttributes(kvs...)
+}
+
+func (s *span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
+
+func keyValue(a dmm.Attribute) attribute.KeyValue {
+	switch v := a.Value.(type) {
+	case string:
+		return attribute.String(a.Key, v)
+	case int:
+		return attribute.Int(a.Key, v)
+	case int64:
+		return attribute.Int64(a.Key, v)
+	case float64:
+		return attribute.Float64(a.Key, v)
+	case bool:
+		return attribute.Bool(a.Key, v)
+	}
+	return attribute.String(a.Key, fmt.Sprint(a.Value))
+}
@@ -0,0 +1,89 @@
+package dmmotel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/usk81/go-dmm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "3" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"result":{"status":400,"message":"BAD REQUEST"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"request":{"parameters":{"hits":"2","offset":"1"}},"result":{"status":200,"result_count":2,"total_count":5,"first_position":1,"items":[{},{}]}}`)
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	cli, err := dmm.New(nil, dmm.SetBaseURL(server.URL), dmm.SetTracer(NewTracer(tp.Tracer("test"))))
+	if err != nil {
+		t.Fatalf("dmm.New returned error: %v", err)
+	}
+
+	opt := &dmm.ItemOptions{Floor: "videoa", Hits: 2, Offset: 1}
+	err = cli.Paginate(context.Background(), opt, func(ctx context.Context) (*dmm.Response, error) {
+		_, r, err := cli.Items.List(ctx, opt)
+		return r, err
+	})
+	if err == nil {
+		t.Fatal("Expected error to be returned")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 5 {
+		t.Fatalf("exported %d spans, expected 5", len(spans))
+	}
+
+	byName := map[string][]tracetest.SpanStub{}
+	for _, s := range spans {
+		byName[s.Name] = append(byName[s.Name], s)
+	}
+	root := byName["dmm.paginate"]
+	if len(root) != 1 || root[0].Status.Code != codes.Error {
+		t.Fatalf("unexpected root span: %+v", root)
+	}
+	calls := byName["affiliate/v3/ItemList"]
+	if len(calls) != 2 {
+		t.Fatalf("exported %d call spans, expected 2", len(calls))
+	}
+	for _, c := range calls {
+		if c.Parent.TraceID() != root[0].SpanContext.TraceID() {
+			t.Errorf("call span is not part of the pagination trace")
+		}
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range calls[0].Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs[dmm.AttrFloor]; v.AsString() != "videoa" {
+		t.Errorf("%s = %v, expected videoa", dmm.AttrFloor, v.Emit())
+	}
+	if v := attrs[dmm.AttrTotalCount]; v.AsInt64() != 5 {
+		t.Errorf("%s = %v, expected 5", dmm.AttrTotalCount, v.Emit())
+	}
+
+	failed := calls[1]
+	if failed.Status.Code != codes.Error {
+		t.Errorf("failed call span status = %v, expected %v", failed.Status.Code, codes.Error)
+	}
+	for _, kv := range failed.Attributes {
+		if kv.Key == dmm.AttrErrorType && kv.Value.AsString() != dmm.ErrorTypeAPI {
+			t.Errorf("%s = %s, expected %s", dmm.AttrErrorType, kv.Value.AsString(), dmm.ErrorTypeAPI)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package dmmtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/usk81/go-dmm"
+)
+
+// ErrConnectionReset is the error returned by the calls FailAfter fails
+var ErrConnectionReset = errors.New("dmmtest: connection reset")
+
+// FailAfter returns a Middleware letting the first n API calls through
+// and failing every later one with ErrConnectionReset, e.g. to test resuming.
+func FailAfter(n int) dmm.Middleware {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	return func(next dmm.Handler) dmm.Handler {
+		return func(ctx context.Context, call *dmm.Call) (*dmm.Response, error) {
+			mu.Lock()
+			calls++
+			fail := calls > n
+			mu.Unlock()
+			if fail {
+				return nil, ErrConnectionReset
+			}
+			return next(ctx, call)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Package dmmtest provides a fake DMM Affiliate API server for tests.
+//
+// The server answers ItemList, ActressSearch, GenreSearch, MakerSearch, SeriesSearch,
+// AuthorSearch and FloorList requests from the entities it holds, honouring the
+// common filters as well as hits and offset.
+//
+//	s := dmmtest.NewServer()
+//	defer s.Close()
+//	s.Items = []dmm.Item{{ContentID: "juy553", Date: "2018-07-25 10:00:00"}}
+//	cli := s.NewClient()
+package dmmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/usk81/go-dmm"
+)
+
+// DefaultHits is the page size used when a request has no hits parameter
+const DefaultHits = 20
+
+// Server is a fake DMM Affiliate API.
+// The entity fields must not be modified while requests are being served.
+type Server struct {
+	*httptest.Server
+
+	Items     []dmm.Item
+	Actresses []dmm.Actress
+	Genres    []dmm.Genre
+	Makers    []dmm.Maker
+	Series    []dmm.Series
+	Authors   []dmm.Author
+	Sites     []dmm.Site
+
+	// MaxOffset rejects requests with a larger offset like the real API does, if it is not 0
+	MaxOffset int
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// NewServer starts a fake API server without any entities
+func NewServer() *Server {
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/affiliate/v3/ItemList", s.items)
+	mux.HandleFunc("/affiliate/v3/ActressSearch", s.actresses)
+	mux.HandleFunc("/affiliate/v3/GenreSearch", s.genres)
+	mux.HandleFunc("/affiliate/v3/MakerSearch", s.makers)
+	mux.HandleFunc("/affiliate/v3/SeriesSearch", s.series)
+	mux.HandleFunc("/affiliate/v3/AuthorSearch", s.authors)
+	mux.HandleFunc("/affiliate/v3/FloorList", s.floors)
+	s.Server = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// NewClient returns a DMM API client sending requests to s
+func (s *Server) NewClient(opts ...dmm.ClientOpt) *dmm.Client {
+	opts = append([]dmm.ClientOpt{dmm.SetBaseURL(s.URL)}, opts...)
+	c, err := dmm.New(nil, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Requests returns the path and query of every request received so far
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// RequestCount returns the number of requests to the endpoint, e.g. "ItemList"
+func (s *Server) RequestCount(endpoint string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if strings.HasPrefix(r, "/affiliate/v3/"+endpoint+"?") || r == "/affiliate/v3/"+endpoint {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Server) record(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.RequestURI())
+		s.mu.Unlock()
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) items(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var vs []interface{}
+	var matched []dmm.Item
+	for _, i := range s.Items {
+		if matchItem(i, q) {
+			matched = append(matched, i)
+		}
+	}
+	if q.Get("sort") == "date" {
+		sort.SliceStable(matched, func(a, b int) bool { return matched[a].Date > matched[b].Date })
+	}
+	for _, i := range matched {
+		vs = append(vs, i)
+	}
+	s.page(w, r, "items", vs, nil)
+}
+
+func matchItem(i dmm.Item, q map[string][]string) bool {
+	get := func(k string) string {
+		if v := q[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	if v := get("service"); v != "" && v != i.ServiceCode {
+		return false
+	}
+	if v := get("floor"); v != "" && v != i.FloorCode {
+		return false
+	}
+	if v := get("cid"); v != "" && v != i.ContentID {
+		return false
+	}
+	if v := get("keyword"); v != "" && !strings.Contains(i.Title, v) {
+		return false
+	}
+	if v := get("gte_date"); v != "" && i.Date < normalizeDate(v) {
+		return false
+	}
+	if v := get("lte_date"); v != "" && i.Date > normalizeDate(v) {
+		return false
+	}
+	if a := get("article"); a != "" {
+		id := get("article_id")
+		found := false
+		for _, c := range i.ItemInfo[a] {
+			if id == "" || c.ID.String() == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeDate converts a date parameter (2006-01-02T15:04:05) into the item date format
+func normalizeDate(s string) string {
+	return strings.Replace(s, "T", " ", 1)
+}
+
+func (s *Server) actresses(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var vs []interface{}
+	for _, a := range s.Actresses {
+		if id := q.Get("actress_id"); id != "" && id != a.ID {
+			continue
+		}
+		if k := q.Get("keyword"); k != "" && !strings.Contains(a.Name, k) && !strings.Contains(a.Ruby, k) {
+			continue
+		}
+		if i := q.Get("initial"); i != "" && !strings.HasPrefix(a.Ruby, i) {
+			continue
+		}
+		vs = append(vs, a)
+	}
+	s.page(w, r, "actress", vs, nil)
+}
+
+func (s *Server) genres(w http.ResponseWriter, r *http.Request) {
+	var vs []interface{}
+	var floor *dmm.Genre
+	for i, g := range s.Genres {
+		if matchEntity(r, g.FloorID, g.Ruby) {
+			vs = append(vs, g)
+			floor = &s.Genres[i]
+		}
+	}
+	var f map[string]string
+	if floor != nil {
+		f = floorFields(floor.SiteName, floor.SiteCode, floor.ServiceName, floor.ServiceCode, floor.FloorID, floor.FloorName, floor.FloorCode)
+	}
+	s.page(w, r, "genre", vs, f)
+}
+
+func (s *Server) makers(w http.ResponseWriter, r *http.Request) {
+	var vs []interface{}
+	var floor *dmm.Maker
+	for i, m := range s.Makers {
+		if matchEntity(r, m.FloorID, m.Ruby) {
+			vs = append(vs, m)
+			floor = &s.Makers[i]
+		}
+	}
+	var f map[string]string
+	if floor != nil {
+		f = floorFields(floor.SiteName, floor.SiteCode, floor.ServiceName, floor.ServiceCode, floor.FloorID, floor.FloorName, floor.FloorCode)
+	}
+	s.page(w, r, "maker", vs, f)
+}
+
+func (s *Server) series(w http.ResponseWriter, r *http.Request) {
+	var vs []interface{}
+	var floor *dmm.Series
+	for i, e := range s.Series {
+		if matchEntity(r, e.FloorID, e.Ruby) {
+			vs = append(vs, e)
+			floor = &s.Series[i]
+		}
+	}
+	var f map[string]string
+	if floor != nil {
+		f = floorFields(floor.SiteName, floor.SiteCode, floor.ServiceName, floor.ServiceCode, floor.FloorID, floor.FloorName, floor.FloorCode)
+	}
+	s.page(w, r, "series", vs, f)
+}
+
+func (s *Server) authors(w http.ResponseWriter, r *http.Request) {
+	var vs []interface{}
+	var floor *dmm.Author
+	for i, a := range s.Authors {
+		if matchEntity(r, a.FloorID, a.Ruby) {
+			vs = append(vs, a)
+			floor = &s.Authors[i]
+		}
+	}
+	var f map[string]string
+	if floor != nil {
+		f = floorFields(floor.SiteName, floor.SiteCode, floor.ServiceName, floor.ServiceCode, floor.FloorID, floor.FloorName, floor.FloorCode)
+	}
+	s.page(w, r, "author", vs, f)
+}
+
+func matchEntity(r *http.Request, floorID, ruby string) bool {
+	q := r.URL.Query()
+	if f := q.Get("floor_id"); f != "" && floorID != "" && f != floorID {
+		return false
+	}
+	if i := q.Get("initial"); i != "" && !strings.HasPrefix(ruby, i) {
+		return false
+	}
+	return true
+}
+
+func floorFields(siteName, siteCode, serviceName, serviceCode, floorID, floorName, floorCode string) map[string]string {
+	return map[string]string{
+		"site_name":    siteName,
+		"site_code":    siteCode,
+		"service_name": serviceName,
+		"service_code": serviceCode,
+		"floor_id":     floorID,
+		"floor_name":   floorName,
+		"floor_code":   floorCode,
+	}
+}
+
+func (s *Server) floors(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"request": map[string]interface{}{"parameters": parameters(r)},
+		"result":  map[string]interface{}{"site": s.Sites},
+	})
+}
+
+// page writes the page of vs selected by hits and offset
+func (s *Server) page(w http.ResponseWriter, r *http.Request, key string, vs []interface{}, extra map[string]string) {
+	q := r.URL.Query()
+	hits, offset := DefaultHits, 1
+	if v, err := strconv.Atoi(q.Get("hits")); err == nil && v > 0 {
+		hits = v
+	}
+	if v, err := strconv.Atoi(q.Get("offset")); err == nil && v > 0 {
+		offset = v
+	}
+	if s.MaxOffset > 0 && offset > s.MaxOffset {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"request": map[string]interface{}{"parameters": parameters(r)},
+			"result": map[string]interface{}{
+				"status":  http.StatusBadRequest,
+				"message": "BAD REQUEST",
+				"errors":  map[string]string{"offset": fmt.Sprintf("offset must be %d or less", s.MaxOffset)},
+			},
+		})
+		return
+	}
+
+	page := []interface{}{}
+	for i := offset - 1; i >= 0 && i < len(vs) && i < offset-1+hits; i++ {
+		page = append(page, vs[i])
+	}
+	result := map[string]interface{}{
+		"status":         http.StatusOK,
+		"result_count":   len(page),
+		"total_count":    len(vs),
+		"first_position": offset,
+		key:              page,
+	}
+	for k, v := range extra {
+		result[k] = v
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"request": map[string]interface{}{"parameters": parameters(r)},
+		"result":  result,
+	})
+}
+
+// parameters echoes the query like the real API does
+func parameters(r *http.Request) map[string]string {
+	ps := map[string]string{}
+	for k, v := range r.URL.Query() {
+		ps[k] = v[0]
+	}
+	return ps
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
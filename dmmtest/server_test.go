@@ -0,0 +1,99 @@
+package dmmtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+)
+
+func TestServer_items(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.Items = []dmm.Item{
+		{ContentID: "a", FloorCode: "videoa", Date: "2020-01-01 10:00:00"},
+		{ContentID: "b", FloorCode: "videoa", Date: "2020-01-03 10:00:00", ItemInfo: map[string][]dmm.ItemComponent{
+			dmm.ItemInfoActress: {{ID: generic.MustString(1), Name: "A"}},
+		}},
+		{ContentID: "c", FloorCode: "videoa", Date: "2020-01-02 10:00:00"},
+		{ContentID: "d", FloorCode: "dvd", Date: "2020-01-02 10:00:00"},
+	}
+	cli := s.NewClient()
+	ctx := context.Background()
+
+	is, r, err := cli.Items.List(ctx, &dmm.ItemOptions{Floor: "videoa", Sort: "date", Hits: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("Items.List returned error: %v", err)
+	}
+	if len(is) != 2 || is[0].ContentID != "c" || is[1].ContentID != "a" {
+		t.Errorf("Items.List returned %+v", is)
+	}
+	if r.TotalCount != 3 || r.ResultCount != 2 || r.FirstPosition != 2 {
+		t.Errorf("Response = %+v", r)
+	}
+
+	is, _, err = cli.Items.List(ctx, &dmm.ItemOptions{GteDate: "2020-01-02T00:00:00", LteDate: "2020-01-02T23:59:59"})
+	if err != nil || len(is) != 2 {
+		t.Errorf("Items.List with date window returned %d items (%v)", len(is), err)
+	}
+
+	is, _, err = cli.Items.List(ctx, &dmm.ItemOptions{Article: "actress", ArticleID: "1"})
+	if err != nil || len(is) != 1 || is[0].ContentID != "b" {
+		t.Errorf("Items.List by article returned %+v (%v)", is, err)
+	}
+
+	if n := s.RequestCount("ItemList"); n != 3 {
+		t.Errorf("RequestCount = %d, expected 3", n)
+	}
+}
+
+func TestServer_maxOffset(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.MaxOffset = 10
+
+	_, _, err := s.NewClient().Items.List(context.Background(), &dmm.ItemOptions{Offset: 11})
+	if _, ok := err.(*dmm.ErrorResponse); !ok {
+		t.Errorf("Items.List returned %v, expected *dmm.ErrorResponse", err)
+	}
+}
+
+func TestServer_genres(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.Genres = []dmm.Genre{
+		{GenreID: "1", Name: "愛", Ruby: "あい", FloorID: "43", FloorCode: "videoa"},
+		{GenreID: "2", Name: "恋", Ruby: "こい", FloorID: "43", FloorCode: "videoa"},
+		{GenreID: "3", Name: "青", Ruby: "あお", FloorID: "91", FloorCode: "comic"},
+	}
+
+	gs, _, err := s.NewClient().Genres.List(context.Background(), &dmm.GenreOptions{FloorID: "43", Initial: "あ"})
+	if err != nil {
+		t.Fatalf("Genres.List returned error: %v", err)
+	}
+	if len(gs) != 1 || gs[0].GenreID != "1" || gs[0].FloorCode != "videoa" {
+		t.Errorf("Genres.List returned %+v", gs)
+	}
+}
+
+func TestFailAfter(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	cli := s.NewClient(dmm.UseMiddleware(FailAfter(2)))
+
+	for i := 0; i < 3; i++ {
+		_, _, err := cli.Items.List(context.Background(), nil)
+		if expected := i >= 2; (err != nil) != expected {
+			t.Errorf("call %d returned error %v", i+1, err)
+		}
+		if i >= 2 && err != ErrConnectionReset {
+			t.Errorf("call %d returned error %v, expected %v", i+1, err, ErrConnectionReset)
+		}
+	}
+	if n := s.RequestCount("ItemList"); n != 2 {
+		t.Errorf("sent %d requests, expected 2", n)
+	}
+}
@@ -0,0 +1,45 @@
+package dmmurl
+
+import (
+	"github.com/usk81/go-dmm"
+)
+
+// Detail returns the PC URL of the product page of the content ID
+func Detail(site, service, floor, cid string) URL {
+	return URL{Site: site, Service: service, Floor: floor, Kind: KindDetail, Params: []Param{{"cid", cid}}}
+}
+
+// List returns the PC URL of the list page of the article, e.g. ArticleMaker
+func List(site, service, floor, article, id string) URL {
+	return URL{Site: site, Service: service, Floor: floor, Kind: KindList, Params: []Param{{"article", article}, {"id", id}}}
+}
+
+// ForItem returns the PC URL of the product page of the item
+func ForItem(site string, i dmm.Item) URL {
+	return Detail(site, i.ServiceCode, i.FloorCode, i.ContentID)
+}
+
+// ForGenre returns the PC URL of the list page of the genre in its floor
+func ForGenre(g dmm.Genre) URL {
+	return List(g.SiteCode, g.ServiceCode, g.FloorCode, ArticleGenre, g.GenreID)
+}
+
+// ForMaker returns the PC URL of the list page of the maker in its floor
+func ForMaker(m dmm.Maker) URL {
+	return List(m.SiteCode, m.ServiceCode, m.FloorCode, ArticleMaker, m.MakerID)
+}
+
+// ForSeries returns the PC URL of the list page of the series in its floor
+func ForSeries(s dmm.Series) URL {
+	return List(s.SiteCode, s.ServiceCode, s.FloorCode, ArticleSeries, s.SeriesID)
+}
+
+// ForAuthor returns the PC URL of the list page of the author in its floor
+func ForAuthor(a dmm.Author) URL {
+	return List(a.SiteCode, a.ServiceCode, a.FloorCode, ArticleAuthor, a.AuthorID)
+}
+
+// ForActress returns the PC URL of the list page of the actress in the floor
+func ForActress(a dmm.Actress, service, floor string) URL {
+	return List(dmm.SiteAdult, service, floor, ArticleActress, a.ID)
+}
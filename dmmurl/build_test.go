@@ -0,0 +1,24 @@
+package dmmurl
+
+import (
+	"testing"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestBuild(t *testing.T) {
+	cases := map[string]string{
+		Detail(dmm.SiteAdult, "digital", "videoa", "1hawa00124").String():                                                                       "https://www.dmm.co.jp/digital/videoa/-/detail/=/cid=1hawa00124/",
+		ForItem(dmm.SiteGeneral, dmm.Item{ServiceCode: "mono", FloorCode: "book", ContentID: "b123"}).WithAffiliateID("affiliate-990").String(): "https://www.dmm.com/mono/book/-/detail/=/cid=b123/affiliate-990",
+		ForGenre(dmm.Genre{GenreID: "1034", SiteCode: dmm.SiteAdult, ServiceCode: "digital", FloorCode: "videoa"}).Smartphone().String():        "https://sp.dmm.co.jp/digital/list/index/shop/videoa/article/keyword/id/1034/",
+		ForMaker(dmm.Maker{MakerID: "1509", SiteCode: dmm.SiteAdult, ServiceCode: "digital", FloorCode: "videoa"}).String():                     "https://www.dmm.co.jp/digital/videoa/-/list/=/article=maker/id=1509/",
+		ForSeries(dmm.Series{SeriesID: "441", SiteCode: dmm.SiteAdult, ServiceCode: "digital", FloorCode: "videoa"}).String():                   "https://www.dmm.co.jp/digital/videoa/-/list/=/article=series/id=441/",
+		ForAuthor(dmm.Author{AuthorID: "217781", SiteCode: dmm.SiteGeneral, ServiceCode: "ebook", FloorCode: "novel"}).String():                 "https://www.dmm.com/ebook/novel/-/list/=/article=author/id=217781/",
+		ForActress(dmm.Actress{ID: "26617"}, "digital", "videoa").WithAffiliateID("affiliate-990").String():                                     "https://www.dmm.co.jp/digital/videoa/-/list/=/article=actress/id=26617/affiliate-990",
+	}
+	for actual, expected := range cases {
+		if actual != expected {
+			t.Errorf("built URL %q, expected %q", actual, expected)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// Package dmmurl parses DMM and FANZA product and list URLs and re-emits them
+// with another affiliate ID, for PC and for smartphones.
+//
+// Three forms are understood:
+//
+//	http://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/affiliate-990                   (PC)
+//	http://sp.dmm.co.jp/mono/detail/index/shop/dvd/cid/juy553/affiliate-990               (smartphone)
+//	https://al.dmm.co.jp/?lurl=https%3A%2F%2Fwww.dmm.co.jp%2F...&af_id=affiliate-990&ch=api (redirect)
+//
+// e.g.
+//
+//	s, err := dmmurl.Rewrite(item.AffiliateURL, "other-991")
+//	u := dmmurl.ForGenre(genre).WithAffiliateID("other-991").Smartphone()
+package dmmurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/usk81/go-dmm"
+)
+
+// Page kinds
+const (
+	KindDetail = "detail"
+	KindList   = "list"
+)
+
+// Articles of list pages
+const (
+	ArticleActress = "actress"
+	ArticleAuthor  = "author"
+	ArticleGenre   = "keyword"
+	ArticleMaker   = "maker"
+	ArticleSeries  = "series"
+)
+
+// Hosts
+const (
+	HostAdult           = "www.dmm.co.jp"
+	HostAdultMobile     = "sp.dmm.co.jp"
+	HostAdultRedirect   = "al.dmm.co.jp"
+	HostGeneral         = "www.dmm.com"
+	HostGeneralMobile   = "sp.dmm.com"
+	HostGeneralRedirect = "al.dmm.com"
+)
+
+// Param is a parameter in the path of a URL, e.g. cid=juy553
+type Param struct {
+	Key   string
+	Value string
+}
+
+// URL is a parsed product or list page URL
+type URL struct {
+	Scheme string
+	// Site is dmm.SiteAdult or dmm.SiteGeneral
+	Site    string
+	Service string
+	Floor   string
+	// Kind is KindDetail or KindList
+	Kind string
+	// Params are the parameters of the page in order, e.g. cid, or article and id
+	Params []Param
+	// AffiliateID is empty for a URL without one
+	AffiliateID string
+
+	// Mobile is true for the smartphone form
+	Mobile bool
+	// Redirect is true for the redirect form, whose extra query parameters (e.g. ch) are kept in RedirectQuery.
+	// The redirect form is emitted with lurl and af_id first.
+	Redirect      bool
+	RedirectQuery url.Values
+}
+
+// Get returns the value of the parameter
+func (u URL) Get(key string) string {
+	for _, p := range u.Params {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// ContentID returns the cid of a detail page
+func (u URL) ContentID() string {
+	return u.Get("cid")
+}
+
+// Article returns the article of a list page, e.g. ArticleActress
+func (u URL) Article() string {
+	return u.Get("article")
+}
+
+// ArticleID returns the ID of the article of a list page
+func (u URL) ArticleID() string {
+	return u.Get("id")
+}
+
+// Parse parses a product or list URL in any of the forms
+func Parse(raw string) (URL, error) {
+	pu, err := url.Parse(raw)
+	if err != nil {
+		return URL{}, err
+	}
+	switch pu.Host {
+	case HostAdultRedirect, HostGeneralRedirect:
+		return parseRedirect(pu)
+	case HostAdult, HostGeneral:
+		return parsePC(pu)
+	case HostAdultMobile, HostGeneralMobile:
+		return parseMobile(pu)
+	}
+	return URL{}, fmt.Errorf("dmmurl: unknown host %q", pu.Host)
+}
+
+func parseRedirect(pu *url.URL) (URL, error) {
+	q := pu.Query()
+	inner := q.Get("lurl")
+	if inner == "" {
+		return URL{}, fmt.Errorf("dmmurl: redirect URL without lurl: %s", pu)
+	}
+	u, err := Parse(inner)
+	if err != nil {
+		return URL{}, err
+	}
+	if u.Redirect {
+		return URL{}, fmt.Errorf("dmmurl: nested redirect URL: %s", pu)
+	}
+	u.AffiliateID = q.Get("af_id")
+	q.Del("lurl")
+	q.Del("af_id")
+	u.Redirect, u.RedirectQuery = true, q
+	return u, nil
+}
+
+// parsePC parses /{service}/{floor}/-/{kind}/=/{key}={value}/.../{affiliate ID}
+func parsePC(pu *url.URL) (URL, error) {
+	u := URL{Scheme: pu.Scheme, Site: site(pu.Host)}
+	i := strings.Index(pu.Path, "/-/")
+	if i < 0 {
+		return URL{}, fmt.Errorf("dmmurl: unknown path %q", pu.Path)
+	}
+	prefix := split(pu.Path[:i])
+	rest := split(pu.Path[i+len("/-/"):])
+	if len(prefix) != 2 || len(rest) < 2 || rest[1] != "=" {
+		return URL{}, fmt.Errorf("dmmurl: unknown path %q", pu.Path)
+	}
+	u.Service, u.Floor, u.Kind = prefix[0], prefix[1], rest[0]
+
+	for _, s := range rest[2:] {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) == 1 {
+			u.AffiliateID = s
+			continue
+		}
+		u.Params = append(u.Params, Param{Key: kv[0], Value: kv[1]})
+	}
+	return u, u.validate()
+}
+
+// parseMobile parses /{service}/{kind}/index/shop/{floor}/{key}/{value}/.../{affiliate ID}
+func parseMobile(pu *url.URL) (URL, error) {
+	u := URL{Scheme: pu.Scheme, Site: site(pu.Host), Mobile: true}
+	ss := split(pu.Path)
+	if len(ss) < 5 || ss[2] != "index" || ss[3] != "shop" {
+		return URL{}, fmt.Errorf("dmmurl: unknown path %q", pu.Path)
+	}
+	u.Service, u.Kind, u.Floor = ss[0], ss[1], ss[4]
+
+	ps := ss[5:]
+	if len(ps)%2 == 1 {
+		u.AffiliateID = ps[len(ps)-1]
+		ps = ps[:len(ps)-1]
+	}
+	for i := 0; i < len(ps); i += 2 {
+		u.Params = append(u.Params, Param{Key: ps[i], Value: ps[i+1]})
+	}
+	return u, u.validate()
+}
+
+func (u URL) validate() error {
+	switch {
+	case u.Kind == KindDetail && u.ContentID() == "":
+		return fmt.Errorf("dmmurl: detail URL without cid")
+	case u.Kind == KindList && u.Article() == "":
+		return fmt.Errorf("dmmurl: list URL without article")
+	case u.Kind != KindDetail && u.Kind != KindList:
+		return fmt.Errorf("dmmurl: unknown page kind %q", u.Kind)
+	}
+	return nil
+}
+
+// WithAffiliateID returns u with the affiliate ID, or without one if it is empty
+func (u URL) WithAffiliateID(id string) URL {
+	u.AffiliateID = id
+	return u
+}
+
+// PC returns the PC form of u
+func (u URL) PC() URL {
+	u.Mobile, u.Redirect = false, false
+	return u
+}
+
+// Smartphone returns the smartphone form of u
+func (u URL) Smartphone() URL {
+	u.Mobile, u.Redirect = true, false
+	return u
+}
+
+// String returns the URL in its form
+func (u URL) String() string {
+	if u.Redirect {
+		inner := u
+		inner.Redirect, inner.AffiliateID = false, ""
+		q := "lurl=" + url.QueryEscape(inner.String())
+		if u.AffiliateID != "" {
+			q += "&af_id=" + url.QueryEscape(u.AffiliateID)
+		}
+		if len(u.RedirectQuery) > 0 {
+			q += "&" + u.RedirectQuery.Encode()
+		}
+		return (&url.URL{Scheme: u.scheme(), Host: u.host(), Path: "/", RawQuery: q}).String()
+	}
+
+	var b strings.Builder
+	b.WriteString(u.scheme() + "://" + u.host() + "/" + u.Service + "/")
+	if u.Mobile {
+		b.WriteString(u.Kind + "/index/shop/" + u.Floor + "/")
+		for _, p := range u.Params {
+			b.WriteString(p.Key + "/" + p.Value + "/")
+		}
+	} else {
+		b.WriteString(u.Floor + "/-/" + u.Kind + "/=/")
+		for _, p := range u.Params {
+			b.WriteString(p.Key + "=" + p.Value + "/")
+		}
+	}
+	b.WriteString(u.AffiliateID)
+	return b.String()
+}
+
+func (u URL) scheme() string {
+	if u.Scheme != "" {
+		return u.Scheme
+	}
+	return "https"
+}
+
+func (u URL) host() string {
+	general := u.Site == dmm.SiteGeneral
+	switch {
+	case u.Redirect && general:
+		return HostGeneralRedirect
+	case u.Redirect:
+		return HostAdultRedirect
+	case u.Mobile && general:
+		return HostGeneralMobile
+	case u.Mobile:
+		return HostAdultMobile
+	case general:
+		return HostGeneral
+	}
+	return HostAdult
+}
+
+// Rewrite re-emits the URL in its form with the affiliate ID
+func Rewrite(raw, affiliateID string) (string, error) {
+	u, err := Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return u.WithAffiliateID(affiliateID).String(), nil
+}
+
+func site(host string) string {
+	if strings.HasSuffix(host, ".dmm.com") {
+		return dmm.SiteGeneral
+	}
+	return dmm.SiteAdult
+}
+
+func split(p string) []string {
+	var ss []string
+	for _, s := range strings.Split(p, "/") {
+		if s != "" {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
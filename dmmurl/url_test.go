@@ -0,0 +1,114 @@
+package dmmurl
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected URL
+	}{
+		{
+			"http://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/affiliate-990",
+			URL{Scheme: "http", Site: dmm.SiteAdult, Service: "mono", Floor: "dvd", Kind: KindDetail,
+				Params: []Param{{"cid", "juy553"}}, AffiliateID: "affiliate-990"},
+		},
+		{
+			"http://sp.dmm.co.jp/mono/detail/index/shop/dvd/cid/juy553/affiliate-990",
+			URL{Scheme: "http", Site: dmm.SiteAdult, Service: "mono", Floor: "dvd", Kind: KindDetail,
+				Params: []Param{{"cid", "juy553"}}, AffiliateID: "affiliate-990", Mobile: true},
+		},
+		{
+			"http://www.dmm.co.jp/digital/videoa/-/list/=/article=actress/id=26617/affiliate-990",
+			URL{Scheme: "http", Site: dmm.SiteAdult, Service: "digital", Floor: "videoa", Kind: KindList,
+				Params: []Param{{"article", "actress"}, {"id", "26617"}}, AffiliateID: "affiliate-990"},
+		},
+		{
+			"https://www.dmm.com/digital/anime/-/list/=/article=keyword/id=6004/sort=date/",
+			URL{Scheme: "https", Site: dmm.SiteGeneral, Service: "digital", Floor: "anime", Kind: KindList,
+				Params: []Param{{"article", "keyword"}, {"id", "6004"}, {"sort", "date"}}},
+		},
+		{
+			"https://al.dmm.co.jp/?lurl=https%3A%2F%2Fwww.dmm.co.jp%2Fdigital%2Fvideoa%2F-%2Fdetail%2F%3D%2Fcid%3D1hawa00124%2F&af_id=affiliate-990&ch=api",
+			URL{Scheme: "https", Site: dmm.SiteAdult, Service: "digital", Floor: "videoa", Kind: KindDetail,
+				Params: []Param{{"cid", "1hawa00124"}}, AffiliateID: "affiliate-990",
+				Redirect: true, RedirectQuery: url.Values{"ch": {"api"}}},
+		},
+	}
+	for _, c := range cases {
+		u, err := Parse(c.raw)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", c.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(u, c.expected) {
+			t.Errorf("Parse(%q) returned %+v, expected %+v", c.raw, u, c.expected)
+		}
+		if s := u.String(); s != c.raw {
+			t.Errorf("URL.String returned %q, expected %q", s, c.raw)
+		}
+	}
+}
+
+func TestParse_error(t *testing.T) {
+	for _, raw := range []string{
+		"://",
+		"https://example.com/digital/videoa/-/detail/=/cid=abc/",
+		"https://www.dmm.co.jp/top/",
+		"https://www.dmm.co.jp/digital/videoa/-/detail/=/",
+		"https://www.dmm.co.jp/digital/videoa/-/list/=/id=1/",
+		"https://www.dmm.co.jp/digital/videoa/-/ranking/=/term=daily/",
+		"https://sp.dmm.co.jp/mono/detail/",
+		"https://al.dmm.co.jp/?af_id=affiliate-990",
+	} {
+		if _, err := Parse(raw); err == nil {
+			t.Errorf("Parse(%q) returned no error", raw)
+		}
+	}
+}
+
+func TestURL_accessors(t *testing.T) {
+	u, _ := Parse("http://www.dmm.co.jp/digital/videoa/-/list/=/article=maker/id=1509/")
+	if u.Article() != ArticleMaker || u.ArticleID() != "1509" || u.ContentID() != "" {
+		t.Errorf("URL returned article %q, id %q, cid %q", u.Article(), u.ArticleID(), u.ContentID())
+	}
+}
+
+func TestURL_forms(t *testing.T) {
+	u, _ := Parse("http://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/affiliate-990")
+
+	cases := map[string]string{
+		u.WithAffiliateID("other-991").String():              "http://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/other-991",
+		u.WithAffiliateID("").String():                       "http://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/",
+		u.Smartphone().String():                              "http://sp.dmm.co.jp/mono/detail/index/shop/dvd/cid/juy553/affiliate-990",
+		u.Smartphone().PC().String():                         "http://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/affiliate-990",
+		u.WithAffiliateID("other-991").Smartphone().String(): "http://sp.dmm.co.jp/mono/detail/index/shop/dvd/cid/juy553/other-991",
+	}
+	for actual, expected := range cases {
+		if actual != expected {
+			t.Errorf("URL returned %q, expected %q", actual, expected)
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	cases := map[string]string{
+		"http://www.dmm.co.jp/digital/videoa/-/detail/=/cid=1hawa00124/affiliate-990":                                                    "http://www.dmm.co.jp/digital/videoa/-/detail/=/cid=1hawa00124/other-991",
+		"http://sp.dmm.co.jp/mono/detail/index/shop/dvd/cid/juy553/affiliate-990":                                                        "http://sp.dmm.co.jp/mono/detail/index/shop/dvd/cid/juy553/other-991",
+		"https://al.dmm.com/?af_id=affiliate-990&ch=api&lurl=https%3A%2F%2Fwww.dmm.com%2Fmono%2Fbook%2F-%2Fdetail%2F%3D%2Fcid%3Db123%2F": "https://al.dmm.com/?lurl=https%3A%2F%2Fwww.dmm.com%2Fmono%2Fbook%2F-%2Fdetail%2F%3D%2Fcid%3Db123%2F&af_id=other-991&ch=api",
+	}
+	for raw, expected := range cases {
+		actual, err := Rewrite(raw, "other-991")
+		if err != nil {
+			t.Errorf("Rewrite(%q) returned error: %v", raw, err)
+		}
+		if actual != expected {
+			t.Errorf("Rewrite(%q) returned %q, expected %q", raw, actual, expected)
+		}
+	}
+}
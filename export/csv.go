@@ -0,0 +1,270 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/usk81/go-dmm"
+)
+
+// Separator joins multiple values in a single CSV cell
+const Separator = "|"
+
+// ItemColumn is a CSV column of items
+type ItemColumn struct {
+	Name  string
+	Value func(dmm.Item) string
+}
+
+// ActressColumn is a CSV column of actresses
+type ActressColumn struct {
+	Name  string
+	Value func(dmm.Actress) string
+}
+
+// ItemColumns are all item columns in their default order
+var ItemColumns = []ItemColumn{
+	{"content_id", func(i dmm.Item) string { return i.ContentID }},
+	{"product_id", func(i dmm.Item) string { return i.ProductID }},
+	{"title", func(i dmm.Item) string { return i.Title }},
+	{"service_code", func(i dmm.Item) string { return i.ServiceCode }},
+	{"service_name", func(i dmm.Item) string { return i.ServiceName }},
+	{"floor_code", func(i dmm.Item) string { return i.FloorCode }},
+	{"floor_name", func(i dmm.Item) string { return i.FloorName }},
+	{"category_name", func(i dmm.Item) string { return i.CategoryName }},
+	{"date", func(i dmm.Item) string { return i.Date }},
+	{"volume", func(i dmm.Item) string { return i.Volume }},
+	{"maker_product", func(i dmm.Item) string { return i.MakerProduct }},
+	{"jancode", func(i dmm.Item) string { return i.JANCode }},
+	{"isbn", func(i dmm.Item) string { return i.ISBN }},
+	{"stock", func(i dmm.Item) string { return i.Stock }},
+	{"url", func(i dmm.Item) string { return i.URL }},
+	{"url_sp", func(i dmm.Item) string { return i.URLMobile }},
+	{"affiliate_url", func(i dmm.Item) string { return i.AffiliateURL }},
+	{"affiliate_url_sp", func(i dmm.Item) string { return i.AffiliateURLMobile }},
+	{"image_list", func(i dmm.Item) string { return i.ImageURL.List }},
+	{"image_small", func(i dmm.Item) string { return i.ImageURL.Small }},
+	{"image_large", func(i dmm.Item) string { return i.ImageURL.Large }},
+	{"price", func(i dmm.Item) string { return i.Prices.Price }},
+	{"list_price", func(i dmm.Item) string { return i.Prices.ListPrice }},
+	{"deliveries", deliveries},
+	{"review_count", func(i dmm.Item) string { return strconv.Itoa(i.Review.Count) }},
+	{"review_average", func(i dmm.Item) string { return i.Review.Average }},
+	{"sample_image_count", func(i dmm.Item) string { return strconv.Itoa(len(i.SampleImageURL.SampleS.Image)) }},
+	{"actress", componentNames(dmm.ItemInfoActress)},
+	{"actress_id", componentIDs(dmm.ItemInfoActress)},
+	{"genre", componentNames(dmm.ItemInfoGenre)},
+	{"genre_id", componentIDs(dmm.ItemInfoGenre)},
+	{"maker", componentNames(dmm.ItemInfoMaker)},
+	{"maker_id", componentIDs(dmm.ItemInfoMaker)},
+	{"series", componentNames(dmm.ItemInfoSeries)},
+	{"series_id", componentIDs(dmm.ItemInfoSeries)},
+	{"label", componentNames(dmm.ItemInfoLabel)},
+	{"label_id", componentIDs(dmm.ItemInfoLabel)},
+	{"director", componentNames(dmm.ItemInfoDirector)},
+	{"director_id", componentIDs(dmm.ItemInfoDirector)},
+	{"author", componentNames(dmm.ItemInfoAuthor)},
+	{"author_id", componentIDs(dmm.ItemInfoAuthor)},
+}
+
+// ActressColumns are all actress columns in their default order
+var ActressColumns = []ActressColumn{
+	{"id", func(a dmm.Actress) string { return a.ID }},
+	{"name", func(a dmm.Actress) string { return a.Name }},
+	{"ruby", func(a dmm.Actress) string { return a.Ruby }},
+	{"bust", func(a dmm.Actress) string { return a.Bust }},
+	{"cup", func(a dmm.Actress) string { return a.Cup }},
+	{"waist", func(a dmm.Actress) string { return a.Waist }},
+	{"hip", func(a dmm.Actress) string { return a.Hip }},
+	{"height", func(a dmm.Actress) string { return a.Height }},
+	{"birthday", func(a dmm.Actress) string { return a.Birthday }},
+	{"blood_type", func(a dmm.Actress) string { return a.BloodType }},
+	{"hobby", func(a dmm.Actress) string { return a.Hobby }},
+	{"prefectures", func(a dmm.Actress) string { return a.Prefectures }},
+	{"image_small", func(a dmm.Actress) string { return a.ImageURL.Small }},
+	{"image_large", func(a dmm.Actress) string { return a.ImageURL.Large }},
+	{"list_url_digital", func(a dmm.Actress) string { return a.ListURL.Digital }},
+	{"list_url_monthly", func(a dmm.Actress) string { return a.ListURL.Monthly }},
+	{"list_url_ppm", func(a dmm.Actress) string { return a.ListURL.PPM }},
+	{"list_url_mono", func(a dmm.Actress) string { return a.ListURL.Mono }},
+	{"list_url_rental", func(a dmm.Actress) string { return a.ListURL.Rental }},
+}
+
+// SelectItemColumns returns the item columns with the given names in the given order
+func SelectItemColumns(names ...string) ([]ItemColumn, error) {
+	cs := make([]ItemColumn, 0, len(names))
+	for _, n := range names {
+		found := false
+		for _, c := range ItemColumns {
+			if c.Name == n {
+				cs = append(cs, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("export: unknown item column %q", n)
+		}
+	}
+	return cs, nil
+}
+
+// SelectActressColumns returns the actress columns with the given names in the given order
+func SelectActressColumns(names ...string) ([]ActressColumn, error) {
+	cs := make([]ActressColumn, 0, len(names))
+	for _, n := range names {
+		found := false
+		for _, c := range ActressColumns {
+			if c.Name == n {
+				cs = append(cs, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("export: unknown actress column %q", n)
+		}
+	}
+	return cs, nil
+}
+
+// ItemCSVWriter writes items as CSV rows, preceded by a header row
+type ItemCSVWriter struct {
+	w       *csv.Writer
+	columns []ItemColumn
+	header  bool
+}
+
+// NewItemCSVWriter returns an ItemCSVWriter writing columns to w.
+// If columns is nil, ItemColumns are written.
+func NewItemCSVWriter(w io.Writer, columns []ItemColumn) *ItemCSVWriter {
+	if columns == nil {
+		columns = ItemColumns
+	}
+	return &ItemCSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+// WriteItems writes a row per item
+func (w *ItemCSVWriter) WriteItems(is ...dmm.Item) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(w.columns))
+	for _, i := range is {
+		for j, c := range w.columns {
+			row[j] = c.Value(i)
+		}
+		if err := w.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data, including the header if no item was written
+func (w *ItemCSVWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *ItemCSVWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	names := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		names[i] = c.Name
+	}
+	return w.w.Write(names)
+}
+
+// ActressCSVWriter writes actresses as CSV rows, preceded by a header row
+type ActressCSVWriter struct {
+	w       *csv.Writer
+	columns []ActressColumn
+	header  bool
+}
+
+// NewActressCSVWriter returns an ActressCSVWriter writing columns to w.
+// If columns is nil, ActressColumns are written.
+func NewActressCSVWriter(w io.Writer, columns []ActressColumn) *ActressCSVWriter {
+	if columns == nil {
+		columns = ActressColumns
+	}
+	return &ActressCSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+// WriteActresses writes a row per actress
+func (w *ActressCSVWriter) WriteActresses(as ...dmm.Actress) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(w.columns))
+	for _, a := range as {
+		for j, c := range w.columns {
+			row[j] = c.Value(a)
+		}
+		if err := w.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data, including the header if no actress was written
+func (w *ActressCSVWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *ActressCSVWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	names := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		names[i] = c.Name
+	}
+	return w.w.Write(names)
+}
+
+func deliveries(i dmm.Item) string {
+	ds := make([]string, len(i.Prices.Deliveries.Delivery))
+	for j, d := range i.Prices.Deliveries.Delivery {
+		ds[j] = d.Type + ":" + d.Price
+	}
+	return strings.Join(ds, Separator)
+}
+
+func componentNames(key string) func(dmm.Item) string {
+	return func(i dmm.Item) string {
+		cs := i.Components(key)
+		ns := make([]string, len(cs))
+		for j, c := range cs {
+			ns[j] = c.Name
+		}
+		return strings.Join(ns, Separator)
+	}
+}
+
+func componentIDs(key string) func(dmm.Item) string {
+	return func(i dmm.Item) string {
+		cs := i.Components(key)
+		ids := make([]string, len(cs))
+		for j, c := range cs {
+			ids[j] = c.ID.String()
+		}
+		return strings.Join(ids, Separator)
+	}
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+)
+
+var testItem = dmm.Item{
+	ContentID: "juy553",
+	Title:     "タイトル, \"引用\"",
+	Prices: dmm.Prices{
+		Price:     "2381~",
+		ListPrice: "3218",
+		Deliveries: dmm.Deliveries{Delivery: []dmm.Delivery{
+			{Type: "stream", Price: "300"},
+			{Type: "download", Price: "980"},
+		}},
+	},
+	Review: dmm.Review{Count: 5, Average: "3.60"},
+	SampleImageURL: dmm.SampleImage{SampleS: dmm.SampleImageURLs{Image: []string{
+		"https://pics.dmm.co.jp/digital/video/juy00553/juy00553-1.jpg",
+		"https://pics.dmm.co.jp/digital/video/juy00553/juy00553-2.jpg",
+	}}},
+	ItemInfo: map[string][]dmm.ItemComponent{
+		dmm.ItemInfoActress: {
+			{ID: generic.MustString(1046150), Name: "壇えみ"},
+			{ID: generic.MustString("1046150_ruby"), Name: "だんえみ"},
+			{ID: generic.MustString(1011199), Name: "北条麻妃"},
+		},
+		dmm.ItemInfoMaker: {
+			{ID: generic.MustString(2661), Name: "マドンナ"},
+		},
+	},
+}
+
+func TestItemCSVWriter(t *testing.T) {
+	cols, err := SelectItemColumns("content_id", "title", "price", "deliveries", "review_average", "sample_image_count", "actress", "actress_id", "maker")
+	if err != nil {
+		t.Fatalf("SelectItemColumns returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w := NewItemCSVWriter(&buf, cols)
+	if err := w.WriteItems(testItem); err != nil {
+		t.Fatalf("ItemCSVWriter.WriteItems returned error: %v", err)
+	}
+	if err := w.WriteItems(dmm.Item{ContentID: "empty"}); err != nil {
+		t.Fatalf("ItemCSVWriter.WriteItems returned error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("ItemCSVWriter.Flush returned error: %v", err)
+	}
+
+	expected := "content_id,title,price,deliveries,review_average,sample_image_count,actress,actress_id,maker\n" +
+		"juy553,\"タイトル, \"\"引用\"\"\",2381~,stream:300|download:980,3.60,2,壇えみ|北条麻妃,1046150|1011199,マドンナ\n" +
+		"empty,,,,,0,,,\n"
+	if buf.String() != expected {
+		t.Errorf("ItemCSVWriter wrote\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestItemCSVWriter_headerOnly(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewItemCSVWriter(&buf, nil)
+	if err := w.Flush(); err != nil {
+		t.Fatalf("ItemCSVWriter.Flush returned error: %v", err)
+	}
+	if got := bytes.Count(buf.Bytes(), []byte(",")); got != len(ItemColumns)-1 {
+		t.Errorf("header has %d separators, expected %d", got, len(ItemColumns)-1)
+	}
+}
+
+func TestSelectItemColumns_unknown(t *testing.T) {
+	if _, err := SelectItemColumns("content_id", "unknown"); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestActressCSVWriter(t *testing.T) {
+	cols, err := SelectActressColumns("id", "name", "ruby", "list_url_digital")
+	if err != nil {
+		t.Fatalf("SelectActressColumns returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w := NewActressCSVWriter(&buf, cols)
+	err = w.WriteActresses(dmm.Actress{
+		ID:      "26617",
+		Name:    "愛内あみ",
+		Ruby:    "あいうちあみ",
+		ListURL: dmm.ListURL{Digital: "http://www.dmm.co.jp/digital/videoa/-/list/=/article=actress/id=26617/affiliate-990"},
+	})
+	if err != nil {
+		t.Fatalf("ActressCSVWriter.WriteActresses returned error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("ActressCSVWriter.Flush returned error: %v", err)
+	}
+
+	expected := "id,name,ruby,list_url_digital\n" +
+		"26617,愛内あみ,あいうちあみ,http://www.dmm.co.jp/digital/videoa/-/list/=/article=actress/id=26617/affiliate-990\n"
+	if buf.String() != expected {
+		t.Errorf("ActressCSVWriter wrote\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,56 @@
+// Package export writes DMM items and actresses as CSV or NDJSON.
+//
+// Writers accept entities page by page, so whole crawls can be streamed to a file:
+//
+//	w := export.NewItemCSVWriter(f, nil)
+//	err := export.Items(ctx, cli, &dmm.ItemOptions{Site: dmm.SiteAdult, Hits: 100, Offset: 1}, w)
+//	...
+//	err = w.Flush()
+package export
+
+import (
+	"context"
+
+	"github.com/usk81/go-dmm"
+)
+
+// ItemWriter writes items
+type ItemWriter interface {
+	WriteItems(...dmm.Item) error
+}
+
+// ActressWriter writes actresses
+type ActressWriter interface {
+	WriteActresses(...dmm.Actress) error
+}
+
+var (
+	_ ItemWriter    = &ItemCSVWriter{}
+	_ ItemWriter    = &NDJSONWriter{}
+	_ ActressWriter = &ActressCSVWriter{}
+	_ ActressWriter = &NDJSONWriter{}
+)
+
+// Items pages through the items matching opt and writes every page to w as soon as it is fetched,
+// so only a single page is held in memory. opt must specify Hits; see dmm.Client.Paginate.
+func Items(ctx context.Context, cli *dmm.Client, opt *dmm.ItemOptions, w ItemWriter) error {
+	return cli.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+		is, r, err := cli.Items.List(ctx, opt)
+		if err != nil {
+			return r, err
+		}
+		return r, w.WriteItems(is...)
+	})
+}
+
+// Actresses pages through the actresses matching opt and writes every page to w as soon as it is fetched,
+// so only a single page is held in memory. opt must specify Hits; see dmm.Client.Paginate.
+func Actresses(ctx context.Context, cli *dmm.Client, opt *dmm.ActressOptions, w ActressWriter) error {
+	return cli.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+		as, r, err := cli.Actresses.List(ctx, opt)
+		if err != nil {
+			return r, err
+		}
+		return r, w.WriteActresses(as...)
+	})
+}
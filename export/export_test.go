@@ -0,0 +1,63 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/usk81/go-dmm"
+)
+
+// pageCounter counts the items written per call
+type pageCounter struct {
+	pages []int
+}
+
+func (p *pageCounter) WriteItems(is ...dmm.Item) error {
+	p.pages = append(p.pages, len(is))
+	return nil
+}
+
+func TestItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits, _ := strconv.Atoi(r.URL.Query().Get("hits"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		const total = 5
+		var items []string
+		for i := offset; i < offset+hits && i <= total; i++ {
+			items = append(items, fmt.Sprintf(`{"content_id":"item%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"request":{"parameters":{"hits":"%d","offset":"%d"}},"result":{"status":200,"result_count":%d,"total_count":%d,"first_position":%d,"items":[%s]}}`,
+			hits, offset, len(items), total, offset, strings.Join(items, ","))
+	}))
+	defer server.Close()
+
+	cli, _ := dmm.New(nil, dmm.SetBaseURL(server.URL))
+
+	pc := &pageCounter{}
+	if err := Items(context.Background(), cli, &dmm.ItemOptions{Hits: 2, Offset: 1}, pc); err != nil {
+		t.Fatalf("Items returned error: %v", err)
+	}
+	if fmt.Sprint(pc.pages) != "[2 2 1]" {
+		t.Errorf("wrote pages %v, expected [2 2 1]", pc.pages)
+	}
+
+	var buf bytes.Buffer
+	cols, _ := SelectItemColumns("content_id")
+	w := NewItemCSVWriter(&buf, cols)
+	if err := Items(context.Background(), cli, &dmm.ItemOptions{Hits: 2, Offset: 1}, w); err != nil {
+		t.Fatalf("Items returned error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("ItemCSVWriter.Flush returned error: %v", err)
+	}
+	expected := "content_id\nitem1\nitem2\nitem3\nitem4\nitem5\n"
+	if buf.String() != expected {
+		t.Errorf("Items wrote\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
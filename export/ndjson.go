@@ -0,0 +1,48 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/usk81/go-dmm"
+)
+
+// NDJSONWriter writes items and actresses as newline delimited JSON
+type NDJSONWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter returns an NDJSONWriter writing to w
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	return &NDJSONWriter{w: bw, enc: enc}
+}
+
+// WriteItems writes a line per item
+func (w *NDJSONWriter) WriteItems(is ...dmm.Item) error {
+	for _, i := range is {
+		if err := w.enc.Encode(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteActresses writes a line per actress
+func (w *NDJSONWriter) WriteActresses(as ...dmm.Actress) error {
+	for _, a := range as {
+		if err := w.enc.Encode(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data
+func (w *NDJSONWriter) Flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewNDJSONWriter(&buf)
+	if err := w.WriteItems(testItem, dmm.Item{ContentID: "second"}); err != nil {
+		t.Fatalf("NDJSONWriter.WriteItems returned error: %v", err)
+	}
+	if err := w.WriteActresses(dmm.Actress{ID: "26617"}); err != nil {
+		t.Fatalf("NDJSONWriter.WriteActresses returned error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("NDJSONWriter.Flush returned error: %v", err)
+	}
+
+	s := bufio.NewScanner(&buf)
+	var lines [][]byte
+	for s.Scan() {
+		lines = append(lines, append([]byte(nil), s.Bytes()...))
+	}
+	if len(lines) != 3 {
+		t.Fatalf("wrote %d lines, expected 3", len(lines))
+	}
+
+	var i dmm.Item
+	if err := json.Unmarshal(lines[0], &i); err != nil {
+		t.Fatalf("line 1 is not an item: %v", err)
+	}
+	if !reflect.DeepEqual(i.Prices, testItem.Prices) || i.Title != testItem.Title {
+		t.Errorf("line 1 decoded to %+v, expected %+v", i, testItem)
+	}
+	var a dmm.Actress
+	if err := json.Unmarshal(lines[2], &a); err != nil || a.ID != "26617" {
+		t.Errorf("line 3 decoded to %+v (%v)", a, err)
+	}
+}
@@ -0,0 +1,244 @@
+// Package feed publishes new releases as RSS 2.0 and Atom feeds,
+// e.g. per floor or per actress, and serves them over HTTP with caching.
+//
+//	b := &feed.Builder{Client: cli, Title: "New videos", Link: "https://example.com/videoa"}
+//	opt := dmm.ItemOptions{APIID: apiID, AffiliateID: affiliateID, Site: dmm.SiteAdult, Service: "digital", Floor: "videoa"}
+//	http.Handle("/feeds/videoa", feed.NewHandler(b, opt, 10*time.Minute))
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+// DefaultHits is the number of items of a feed when the options specify no hits
+const DefaultHits = 20
+
+const dateFormat = "2006-01-02 15:04:05"
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// Builder builds feeds of the newest items of queries
+type Builder struct {
+	Client *dmm.Client
+
+	// Title, Link and Description describe the feeds
+	Title       string
+	Link        string
+	Description string
+	// Author of Atom feeds, Title if empty
+	Author string
+
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+}
+
+// Feed is a list of items ready to be rendered
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	Updated     time.Time
+	Items       []dmm.Item
+}
+
+// Build fetches the newest items matching opt, sorted by date
+func (b *Builder) Build(ctx context.Context, opt dmm.ItemOptions) (*Feed, error) {
+	opt.Sort = "date"
+	if opt.Hits == 0 {
+		opt.Hits = DefaultHits
+	}
+	if opt.Offset == 0 {
+		opt.Offset = 1
+	}
+	is, _, err := b.Client.Items.List(ctx, &opt)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Feed{
+		Title:       b.Title,
+		Link:        b.Link,
+		Description: b.Description,
+		Author:      b.Author,
+		Updated:     b.now(),
+		Items:       is,
+	}
+	if f.Author == "" {
+		f.Author = f.Title
+	}
+	if len(is) > 0 {
+		if t, ok := released(is[0]); ok {
+			f.Updated = t
+		}
+	}
+	return f, nil
+}
+
+func (b *Builder) now() time.Time {
+	if b.Now != nil {
+		return b.Now()
+	}
+	return time.Now()
+}
+
+// RSS renders the feed as RSS 2.0
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+		},
+	}
+	for _, i := range f.Items {
+		ri := rssItem{
+			Title: i.Title,
+			Link:  link(i),
+			GUID:  rssGUID{IsPermaLink: "false", Value: i.ContentID},
+		}
+		if t, ok := released(i); ok {
+			ri.PubDate = t.Format(time.RFC1123Z)
+		}
+		if th := thumbnail(i); th != "" {
+			ri.Enclosure = &rssEnclosure{URL: th, Length: "0", Type: "image/jpeg"}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, ri)
+	}
+	return marshal(doc)
+}
+
+// Atom renders the feed as Atom
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atom{
+		Title:   f.Title,
+		ID:      f.Link,
+		Links:   []atomLink{{Href: f.Link, Rel: "alternate"}},
+		Updated: f.Updated.Format(time.RFC3339),
+		Author:  atomAuthor{Name: f.Author},
+	}
+	if f.Description != "" {
+		doc.Subtitle = f.Description
+	}
+	for _, i := range f.Items {
+		e := atomEntry{
+			Title: i.Title,
+			ID:    i.URL,
+			Links: []atomLink{{Href: link(i), Rel: "alternate"}},
+		}
+		if e.ID == "" {
+			e.ID = "urn:dmm:" + i.ContentID
+		}
+		t, ok := released(i)
+		if !ok {
+			t = f.Updated
+		}
+		e.Updated = t.Format(time.RFC3339)
+		e.Published = e.Updated
+		if th := thumbnail(i); th != "" {
+			e.Links = append(e.Links, atomLink{Href: th, Rel: "enclosure", Type: "image/jpeg"})
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+	return marshal(doc)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// link returns the affiliate link of the item, or its plain URL without one
+func link(i dmm.Item) string {
+	if i.AffiliateURL != "" {
+		return i.AffiliateURL
+	}
+	return i.URL
+}
+
+// thumbnail returns the list image of the item, or its small or large image without one
+func thumbnail(i dmm.Item) string {
+	for _, u := range []string{i.ImageURL.List, i.ImageURL.Small, i.ImageURL.Large} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// released returns the release date of the item, which is in JST
+func released(i dmm.Item) (time.Time, bool) {
+	t, err := time.ParseInLocation(dateFormat, i.Date, jst)
+	return t, err == nil
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title     string        `xml:"title"`
+	Link      string        `xml:"link"`
+	GUID      rssGUID       `xml:"guid"`
+	PubDate   string        `xml:"pubDate,omitempty"`
+	Enclosure *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type atom struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Links    []atomLink  `xml:"link"`
+	Updated  string      `xml:"updated"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Links     []atomLink `xml:"link"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+}
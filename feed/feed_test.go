@@ -0,0 +1,120 @@
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+func testServer() *dmmtest.Server {
+	s := dmmtest.NewServer()
+	s.Items = []dmm.Item{
+		{
+			ContentID: "abc00001", ServiceCode: "digital", FloorCode: "videoa", Title: "old & first",
+			Date: "2020-01-01 10:00:00", URL: "https://www.dmm.co.jp/digital/videoa/-/detail/=/cid=abc00001/",
+			AffiliateURL: "https://www.dmm.co.jp/digital/videoa/-/detail/=/cid=abc00001/affiliate-990",
+			ImageURL:     dmm.ImageURL{List: "https://pics.dmm.co.jp/abc00001pt.jpg", Large: "https://pics.dmm.co.jp/abc00001pl.jpg"},
+		},
+		{
+			ContentID: "abc00002", ServiceCode: "digital", FloorCode: "videoa", Title: "new",
+			Date: "2020-01-02 10:00:00", URL: "https://www.dmm.co.jp/digital/videoa/-/detail/=/cid=abc00002/",
+		},
+		{ContentID: "other", ServiceCode: "digital", FloorCode: "videoc", Date: "2020-01-03 10:00:00"},
+	}
+	return s
+}
+
+func testBuilder(s *dmmtest.Server) *Builder {
+	return &Builder{
+		Client:      s.NewClient(),
+		Title:       "New videos",
+		Link:        "https://example.com/videoa",
+		Description: "videoa releases",
+		Now:         func() time.Time { return time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC) },
+	}
+}
+
+var testOptions = dmm.ItemOptions{Site: dmm.SiteAdult, Service: "digital", Floor: "videoa"}
+
+func TestBuilder_Build(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	f, err := testBuilder(server).Build(context.Background(), testOptions)
+	if err != nil {
+		t.Fatalf("Builder.Build returned error: %v", err)
+	}
+	if len(f.Items) != 2 || f.Items[0].ContentID != "abc00002" {
+		t.Errorf("Builder.Build returned items %+v, expected the newest first", f.Items)
+	}
+	if !f.Updated.Equal(time.Date(2020, 1, 2, 1, 0, 0, 0, time.UTC)) || f.Author != "New videos" {
+		t.Errorf("Builder.Build returned updated %v, author %q", f.Updated, f.Author)
+	}
+	if r := server.Requests()[0]; !strings.Contains(r, "sort=date") || !strings.Contains(r, "hits=20") {
+		t.Errorf("Builder.Build requested %s", r)
+	}
+}
+
+func TestFeed_RSS(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	f, _ := testBuilder(server).Build(context.Background(), testOptions)
+
+	data, err := f.RSS()
+	if err != nil {
+		t.Fatalf("Feed.RSS returned error: %v", err)
+	}
+	var doc rss
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Feed.RSS wrote invalid XML: %v\n%s", err, data)
+	}
+	if doc.Version != "2.0" || doc.Channel.Title != "New videos" || doc.Channel.LastBuildDate != "Thu, 02 Jan 2020 10:00:00 +0900" {
+		t.Errorf("Feed.RSS wrote channel %+v", doc.Channel)
+	}
+	if len(doc.Channel.Items) != 2 {
+		t.Fatalf("Feed.RSS wrote %d items, expected 2", len(doc.Channel.Items))
+	}
+	old := doc.Channel.Items[1]
+	if old.Title != "old & first" || old.Link != "https://www.dmm.co.jp/digital/videoa/-/detail/=/cid=abc00001/affiliate-990" ||
+		old.GUID.Value != "abc00001" || old.PubDate != "Wed, 01 Jan 2020 10:00:00 +0900" ||
+		old.Enclosure == nil || old.Enclosure.URL != "https://pics.dmm.co.jp/abc00001pt.jpg" {
+		t.Errorf("Feed.RSS wrote item %+v", old)
+	}
+	if n := doc.Channel.Items[0]; n.Link != "https://www.dmm.co.jp/digital/videoa/-/detail/=/cid=abc00002/" || n.Enclosure != nil {
+		t.Errorf("Feed.RSS wrote item %+v", n)
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	f, _ := testBuilder(server).Build(context.Background(), testOptions)
+
+	data, err := f.Atom()
+	if err != nil {
+		t.Fatalf("Feed.Atom returned error: %v", err)
+	}
+	if !strings.Contains(string(data), `<feed xmlns="http://www.w3.org/2005/Atom">`) {
+		t.Errorf("Feed.Atom wrote no Atom namespace:\n%s", data)
+	}
+	var doc atom
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Feed.Atom wrote invalid XML: %v\n%s", err, data)
+	}
+	if doc.Title != "New videos" || doc.Updated != "2020-01-02T10:00:00+09:00" || doc.Author.Name != "New videos" || doc.Subtitle != "videoa releases" {
+		t.Errorf("Feed.Atom wrote feed %+v", doc)
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("Feed.Atom wrote %d entries, expected 2", len(doc.Entries))
+	}
+	e := doc.Entries[1]
+	if e.ID != "https://www.dmm.co.jp/digital/videoa/-/detail/=/cid=abc00001/" || e.Published != "2020-01-01T10:00:00+09:00" || len(e.Links) != 2 ||
+		e.Links[1].Rel != "enclosure" || e.Links[1].Href != "https://pics.dmm.co.jp/abc00001pt.jpg" {
+		t.Errorf("Feed.Atom wrote entry %+v", e)
+	}
+}
@@ -0,0 +1,198 @@
+package feed
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+// Formats served by Handler
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+)
+
+var contentTypes = map[string]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+}
+
+// Defaults of Handler
+const (
+	// DefaultRetryDelay is how long a failed build is not retried if Handler.RetryDelay is 0
+	DefaultRetryDelay = time.Minute
+	// DefaultBuildTimeout is how long a build may take if Handler.BuildTimeout is 0
+	DefaultBuildTimeout = 30 * time.Second
+)
+
+// Handler serves the feed of a query, as RSS or as Atom if the format query parameter is "atom".
+// The feed is built at most once per TTL, with both formats rendered from the same build;
+// if rebuilding it fails, the stale feed is served and the build is retried after RetryDelay.
+// Builds are not tied to a request, so a client going away does not fail them for the others,
+// and are canceled after BuildTimeout.
+// Responses carry an ETag, and requests with a matching If-None-Match are answered with 304.
+type Handler struct {
+	Builder *Builder
+	Options dmm.ItemOptions
+	TTL     time.Duration
+	// RetryDelay is how long a failed build is not retried, DefaultRetryDelay if 0
+	RetryDelay time.Duration
+	// BuildTimeout bounds a build, DefaultBuildTimeout if 0
+	BuildTimeout time.Duration
+
+	mu     sync.Mutex
+	cached *snapshot
+	err    error
+	// retry is when a failed build may be retried
+	retry time.Time
+	// building is closed when the running build is done, nil if there is none
+	building chan struct{}
+}
+
+// snapshot is a built feed rendered in every format
+type snapshot struct {
+	docs    map[string]rendered
+	expires time.Time
+}
+
+type rendered struct {
+	body []byte
+	etag string
+}
+
+// NewHandler returns a Handler serving the feed of opt, rebuilt at most every ttl
+func NewHandler(b *Builder, opt dmm.ItemOptions, ttl time.Duration) *Handler {
+	return &Handler{Builder: b, Options: opt, TTL: ttl}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	format := FormatRSS
+	if r.URL.Query().Get("format") == FormatAtom {
+		format = FormatAtom
+	}
+
+	doc, err := h.render(r.Context(), format)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("ETag", doc.etag)
+	if h.TTL > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.TTL/time.Second)))
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" && strings.Contains(match, doc.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(doc.body)
+}
+
+// render returns the cached document of the format.
+// If the feed has expired, it waits for a rebuild, started unless one is running,
+// and returns the stale document if the rebuild fails or ctx is done first.
+// Within RetryDelay of a failed build, the stale document or the error is returned right away.
+func (h *Handler) render(ctx context.Context, format string) (rendered, error) {
+	h.mu.Lock()
+	s := h.cached
+	now := h.Builder.now()
+	if s != nil && now.Before(s.expires) {
+		h.mu.Unlock()
+		return s.docs[format], nil
+	}
+	if h.building == nil && h.err != nil && now.Before(h.retry) {
+		err := h.err
+		h.mu.Unlock()
+		if s != nil {
+			return s.docs[format], nil
+		}
+		return rendered{}, err
+	}
+	done := h.building
+	if done == nil {
+		done = make(chan struct{})
+		h.building = done
+		go h.rebuild(done)
+	}
+	h.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if s != nil {
+			return s.docs[format], nil
+		}
+		return rendered{}, ctx.Err()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cached == nil {
+		return rendered{}, h.err
+	}
+	return h.cached.docs[format], nil
+}
+
+// rebuild builds the feed, replacing the cached one if it succeeds, and closes done
+func (h *Handler) rebuild(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.buildTimeout())
+	s, err := h.build(ctx)
+	cancel()
+	h.mu.Lock()
+	if err == nil {
+		h.cached = s
+	} else {
+		h.retry = h.Builder.now().Add(h.retryDelay())
+	}
+	h.err = err
+	h.building = nil
+	h.mu.Unlock()
+	close(done)
+}
+
+func (h *Handler) build(ctx context.Context) (*snapshot, error) {
+	f, err := h.Builder.Build(ctx, h.Options)
+	if err != nil {
+		return nil, err
+	}
+	s := &snapshot{docs: map[string]rendered{}, expires: h.Builder.now().Add(h.TTL)}
+	for format, render := range map[string]func() ([]byte, error){FormatRSS: f.RSS, FormatAtom: f.Atom} {
+		body, err := render()
+		if err != nil {
+			return nil, err
+		}
+		sum := sha1.Sum(body)
+		s.docs[format] = rendered{body: body, etag: `"` + hex.EncodeToString(sum[:]) + `"`}
+	}
+	return s, nil
+}
+
+func (h *Handler) retryDelay() time.Duration {
+	if h.RetryDelay > 0 {
+		return h.RetryDelay
+	}
+	return DefaultRetryDelay
+}
+
+func (h *Handler) buildTimeout() time.Duration {
+	if h.BuildTimeout > 0 {
+		return h.BuildTimeout
+	}
+	return DefaultBuildTimeout
+}
@@ -0,0 +1,167 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestHandler(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	now := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	b := testBuilder(server)
+	b.Now = func() time.Time { return now }
+	h := NewHandler(b, testOptions, time.Minute)
+
+	get := func(target string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("/feed", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/rss+xml; charset=utf-8" || !strings.Contains(w.Body.String(), "<rss") {
+		t.Fatalf("Handler returned %d %s:\n%s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	if w.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Errorf("Handler returned Cache-Control %q", w.Header().Get("Cache-Control"))
+	}
+	etag := w.Header().Get("ETag")
+
+	if w := get("/feed", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Handler returned %d for a matching If-None-Match", w.Code)
+	}
+	if n := server.RequestCount("ItemList"); n != 1 {
+		t.Errorf("Handler sent %d requests, expected the feed to be cached", n)
+	}
+
+	w = get("/feed?format=atom", nil)
+	if w.Header().Get("Content-Type") != "application/atom+xml; charset=utf-8" || !strings.Contains(w.Body.String(), "<feed") {
+		t.Errorf("Handler returned %s:\n%s", w.Header().Get("Content-Type"), w.Body)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Error("Handler returned the ETag of the RSS feed for Atom")
+	}
+	if n := server.RequestCount("ItemList"); n != 1 {
+		t.Errorf("Handler sent %d requests, expected Atom to be rendered from the same build", n)
+	}
+
+	now = now.Add(time.Minute)
+	get("/feed", nil)
+	if n := server.RequestCount("ItemList"); n != 2 {
+		t.Errorf("Handler sent %d requests, expected the expired feed to be rebuilt", n)
+	}
+}
+
+func TestHandler_stale(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	now := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	fail := false
+	b := testBuilder(server)
+	b.Client = server.NewClient(dmm.UseMiddleware(func(next dmm.Handler) dmm.Handler {
+		return func(ctx context.Context, call *dmm.Call) (*dmm.Response, error) {
+			if fail {
+				return nil, errors.New("connection reset")
+			}
+			return next(ctx, call)
+		}
+	}))
+	b.Now = func() time.Time { return now }
+	h := NewHandler(b, testOptions, time.Minute)
+
+	fail = true
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Handler returned %d without a feed, expected %d", w.Code, http.StatusBadGateway)
+	}
+
+	fail = false
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	if w.Code != http.StatusBadGateway || server.RequestCount("ItemList") != 0 {
+		t.Errorf("Handler returned %d, expected the failed build not to be retried yet", w.Code)
+	}
+
+	now = now.Add(DefaultRetryDelay)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Handler returned %d after the retry delay", w.Code)
+	}
+
+	fail = true
+	now = now.Add(time.Hour)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	if w.Code != http.StatusOK || w.Body.String() != body {
+		t.Errorf("Handler returned %d, expected the stale feed", w.Code)
+	}
+
+	fail = false
+	n := server.RequestCount("ItemList")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	if w.Code != http.StatusOK || w.Body.String() != body || server.RequestCount("ItemList") != n {
+		t.Errorf("Handler returned %d, expected the stale feed without a rebuild", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/feed", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Handler returned %d for POST", w.Code)
+	}
+}
+
+func TestHandler_canceled(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	h := NewHandler(testBuilder(server), testOptions, time.Minute)
+
+	// the build outlives the request that started it
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/feed", nil).WithContext(ctx))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Handler returned %d after a canceled request, expected %d", w.Code, http.StatusOK)
+	}
+	if n := server.RequestCount("ItemList"); n != 1 {
+		t.Errorf("Handler sent %d requests, expected the build to be shared", n)
+	}
+}
+
+func TestHandler_timeout(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	b := testBuilder(server)
+	b.Client = server.NewClient(dmm.UseMiddleware(func(next dmm.Handler) dmm.Handler {
+		return func(ctx context.Context, call *dmm.Call) (*dmm.Response, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+	}))
+	h := NewHandler(b, testOptions, time.Minute)
+	h.BuildTimeout = 10 * time.Millisecond
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Handler returned %d for a build timing out, expected %d", w.Code, http.StatusBadGateway)
+	}
+}
@@ -73,14 +73,14 @@ func (r *floorRoot) populatePageValues(res *Response) {
 	res.Parameters = r.Request.Parameters
 }
 
-func (s *FloorsServiceOp) list(ctx context.Context, path string) (floorResult, *Response, error) {
+func (s *FloorsServiceOp) list(ctx context.Context, path string, opt ListOptions) (floorResult, *Response, error) {
 	req, err := s.client.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return floorResult{}, nil, err
 	}
 
 	var root floorRoot
-	resp, err := s.client.Do(ctx, req, &root)
+	resp, err := s.client.do(ctx, &Call{Request: req, Options: listOptions(opt), Value: &root})
 	if err != nil {
 		return floorResult{}, resp, err
 	}
@@ -112,7 +112,7 @@ func (s *FloorsServiceOp) Unmarshal(ctx context.Context, opt *FloorOptions, out
 	}
 	var res floorResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return r, err
 	}
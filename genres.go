@@ -64,6 +64,13 @@ type Genre struct {
 	FloorCode   string
 }
 
+// GenreIndex is an index of genres by initial
+type GenreIndex struct {
+	// Initials are the indexed initials in gojūon order
+	Initials []string
+	Genres   map[string][]Genre
+}
+
 // GenreOptions specifies the optional parameters to various List methods
 type GenreOptions struct {
 	APIID       string `json:"api_id" url:"api_id"`
@@ -88,20 +95,21 @@ type internalGenreOptions struct {
 }
 
 func (r *genreRoot) populatePageValues(res *Response) {
+	res.ResultStatus = r.Result.Status.Int()
 	res.FirstPosition = r.Result.FirstPosition.Int()
 	res.ResultCount = r.Result.ResultCount.Int()
 	res.TotalCount = r.Result.TotalCount.Int()
 	res.Parameters = r.Request.Parameters.Convert()
 }
 
-func (s *GenresServiceOp) list(ctx context.Context, path string) (genreResult, *Response, error) {
+func (s *GenresServiceOp) list(ctx context.Context, path string, opt ListOptions) (genreResult, *Response, error) {
 	req, err := s.client.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return genreResult{}, nil, err
 	}
 
 	var root genreRoot
-	resp, err := s.client.Do(ctx, req, &root)
+	resp, err := s.client.do(ctx, &Call{Request: req, Options: listOptions(opt), Value: &root})
 	if err != nil {
 		return genreResult{}, resp, err
 	}
@@ -126,7 +134,7 @@ func (s *GenresServiceOp) List(ctx context.Context, opt *GenreOptions) ([]Genre,
 	}
 	var res genreResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return nil, r, err
 	}
@@ -155,7 +163,7 @@ func (s *GenresServiceOp) Unmarshal(ctx context.Context, opt *GenreOptions, out
 	}
 	var res genreResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return r, err
 	}
@@ -168,6 +176,66 @@ func (s *GenresServiceOp) Unmarshal(ctx context.Context, opt *GenreOptions, out
 	return r, err
 }
 
+// ResolveGenre returns the genre of the floor whose name or reading best matches name, as scored by kana.Match,
+// searching every page of opt. ErrNotResolved is returned if no genre scores ResolveThreshold.
+func ResolveGenre(ctx context.Context, c *Client, opt *GenreOptions, name string) (Genre, *Response, error) {
+	var o GenreOptions
+	if opt != nil {
+		o = *opt
+	}
+	resolvePage(&o.Hits, &o.Offset)
+
+	var best Genre
+	var resp *Response
+	m := &matcher{name: name}
+	err := c.resolve(ctx, &o, m, func(ctx context.Context) (*Response, error) {
+		gs, r, err := c.Genres.List(ctx, &o)
+		resp = r
+		for _, g := range gs {
+			if m.consider(g.Name, g.Ruby) {
+				best = g
+			}
+		}
+		return r, err
+	})
+	if err != nil {
+		return Genre{}, resp, err
+	}
+	return best, resp, nil
+}
+
+// IndexGenres fetches every page of genres of opt for each initial of the gojūon table, or only for opt.Initial if it is set,
+// running at most concurrency initials at once (DefaultIndexConcurrency if 0).
+// An *InitialError is returned if opt.Initial is not a kana.
+func IndexGenres(ctx context.Context, c *Client, opt *GenreOptions, concurrency int) (GenreIndex, error) {
+	var o GenreOptions
+	if opt != nil {
+		o = *opt
+	}
+	initials, lists, err := fetchIndex(ctx, o.Initial, concurrency, func(ctx context.Context, initial string) (interface{}, error) {
+		po := o
+		po.Initial = initial
+		indexPage(&po.Hits, &po.Offset)
+
+		var gs []Genre
+		err := c.Paginate(ctx, &po, func(ctx context.Context) (*Response, error) {
+			page, r, err := c.Genres.List(ctx, &po)
+			gs = append(gs, page...)
+			return r, err
+		})
+		return gs, err
+	})
+	if err != nil {
+		return GenreIndex{}, err
+	}
+
+	idx := GenreIndex{Initials: initials, Genres: make(map[string][]Genre, len(lists))}
+	for initial, l := range lists {
+		idx.Genres[initial] = l.([]Genre)
+	}
+	return idx, nil
+}
+
 // Next updates offset
 func (o *GenreOptions) Next() (err error) {
 	o.Offset, err = nextOffset(o.Hits, o.Offset)
@@ -327,3 +327,28 @@ func TestGenres_First(t *testing.T) {
 		t.Errorf("Response.FirstPosition returned %+v, expected %+v", r.FirstPosition, re.FirstPosition)
 	}
 }
+
+func TestResolveGenre(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+genreBasePath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, testGenresRequest)
+	})
+
+	for _, name := range []string{`くのいち`, `クノイチ`, `kunoichi`} {
+		actual, _, err := ResolveGenre(ctx, client, &GenreOptions{FloorID: "43"}, name)
+		if err != nil {
+			t.Errorf("ResolveGenre(%q) returned error: %v", name, err)
+			continue
+		}
+		if actual.GenreID != `1075` {
+			t.Errorf("ResolveGenre(%q) returned %+v, expected GenreID 1075", name, actual)
+		}
+	}
+
+	if _, _, err := ResolveGenre(ctx, client, &GenreOptions{FloorID: "43"}, `該当なし`); err != ErrNotResolved {
+		t.Errorf("ResolveGenre returned %v, expected %v", err, ErrNotResolved)
+	}
+}
@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+// DefaultCacheTTL is how long item pages are kept in Graph.Cache if no CacheTTL is set
+const DefaultCacheTTL = 10 * time.Minute
+
+// page is a page of items as kept in Graph.Cache
+type page struct {
+	Items []dmm.Item `json:"items"`
+	// Parameters are the request parameters echoed by the API, without the API ID
+	Parameters    *dmm.ItemOptions `json:"parameters"`
+	ResultStatus  int              `json:"result_status"`
+	TotalCount    int              `json:"total_count"`
+	FirstPosition int              `json:"first_position"`
+}
+
+// newPage returns the page of the items of r
+func newPage(is []dmm.Item, r *dmm.Response) page {
+	p := page{Items: is, ResultStatus: r.ResultStatus, TotalCount: r.TotalCount, FirstPosition: r.FirstPosition}
+	if o, ok := r.Parameters.(*dmm.ItemOptions); ok && o != nil {
+		po := *o
+		po.APIID = ""
+		p.Parameters = &po
+	}
+	return p
+}
+
+// response returns the Response of p for the request of opt, as if it had been fetched
+func (p page) response(opt *dmm.ItemOptions) *dmm.Response {
+	po := *opt
+	if p.Parameters != nil {
+		po = *p.Parameters
+		po.APIID = opt.APIID
+	}
+	return &dmm.Response{
+		Response: &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		},
+		Parameters:    &po,
+		ResultStatus:  p.ResultStatus,
+		ResultCount:   len(p.Items),
+		TotalCount:    p.TotalCount,
+		FirstPosition: p.FirstPosition,
+	}
+}
+
+// cachedPage returns the page cached for key, if any
+func (g *Graph) cachedPage(key string) (page, bool) {
+	body, ok := g.Cache.Get(key)
+	if !ok {
+		return page{}, false
+	}
+	var p page
+	if err := json.Unmarshal(body, &p); err != nil {
+		return page{}, false
+	}
+	return p, true
+}
+
+// cachePage stores p for key
+func (g *Graph) cachePage(key string, p page) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	ttl := g.CacheTTL
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	g.Cache.Set(key, body, ttl)
+}
+
+// pageKey identifies the page of opt.
+// The affiliate ID is part of the key, as it is embedded in the affiliate URLs of the items;
+// the API ID is not, so the pages are shared by the API IDs of an affiliate.
+func pageKey(opt dmm.ItemOptions) string {
+	opt.APIID = ""
+	return fmt.Sprintf("graph:%+v", opt)
+}
@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestGraph_cachePage(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := &Graph{Cache: &dmm.MemoryCache{Now: func() time.Time { return now }}}
+
+	if _, ok := g.cachedPage("k"); ok {
+		t.Error("Graph.cachedPage of an empty cache returned a page")
+	}
+	g.cachePage("k", page{Items: []dmm.Item{{ContentID: "a"}}, TotalCount: 1, FirstPosition: 1})
+	if p, ok := g.cachedPage("k"); !ok || p.Items[0].ContentID != "a" || p.TotalCount != 1 {
+		t.Errorf("Graph.cachedPage returned %+v, %v", p, ok)
+	}
+
+	now = now.Add(DefaultCacheTTL)
+	if _, ok := g.cachedPage("k"); ok {
+		t.Error("Graph.cachedPage returned an expired page")
+	}
+}
+
+func TestPageKey(t *testing.T) {
+	a := dmm.ItemOptions{APIID: "a", Site: dmm.SiteAdult, Article: "maker", ArticleID: "1", Offset: 1}
+	b := a
+	b.APIID = "b"
+	if pageKey(a) != pageKey(b) {
+		t.Errorf("pageKey depends on the API ID: %q, %q", pageKey(a), pageKey(b))
+	}
+	b.AffiliateID = "aff-990"
+	if pageKey(a) == pageKey(b) {
+		t.Errorf("pageKey ignores the affiliate ID: %q", pageKey(a))
+	}
+	b.Offset = 101
+	if pageKey(a) == pageKey(b) {
+		t.Errorf("pageKey ignores the offset: %q", pageKey(a))
+	}
+}
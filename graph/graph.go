@@ -0,0 +1,230 @@
+// Package graph navigates the relationships between entities of the catalog,
+// e.g. from an actress to her items, the genres, makers and series she appears in and her co-stars,
+// or from a maker or series to the summary of its items.
+//
+//	g := &graph.Graph{Client: cli, APIID: apiID, AffiliateID: affiliateID}
+//	p, err := g.Actress(ctx, "1011199")
+//	for _, c := range p.CoStars {
+//		fmt.Println(c.Name, c.Count)
+//	}
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+const (
+	// DefaultHits is the page size used when Graph.Hits is 0
+	DefaultHits = 100
+	// DefaultMaxItems is the number of items aggregated when Graph.MaxItems is 0
+	DefaultMaxItems = 1000
+	// DefaultTop is the length of the rankings when Graph.Top is 0
+	DefaultTop = 10
+)
+
+// Graph answers relationship queries with the services of a client
+type Graph struct {
+	Client      *dmm.Client
+	APIID       string
+	AffiliateID string
+
+	// Site of the items, dmm.SiteAdult if empty
+	Site string
+	// Hits is the page size of item requests
+	Hits int
+	// MaxItems is the most items aggregated into a profile or summary, newest first
+	MaxItems int
+	// Top is the length of the rankings of profiles and summaries
+	Top int
+
+	// Cache keeps the fetched item pages for CacheTTL, DefaultCacheTTL if 0, if it is not nil.
+	// Pages are kept per affiliate ID, as their affiliate URLs embed it.
+	Cache    dmm.Cache
+	CacheTTL time.Duration
+}
+
+// Count is an entity of a ranking and the number of items it appears in
+type Count struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ActressProfile is an actress with the aggregates of her items
+type ActressProfile struct {
+	Actress dmm.Actress `json:"actress"`
+	// TotalItems is the number of items of the actress; Items holds at most Graph.MaxItems of them
+	TotalItems int        `json:"total_items"`
+	Items      []dmm.Item `json:"items"`
+
+	// Genres, Makers and Series are ranked by the number of items they appear in
+	Genres []Count `json:"genres"`
+	Makers []Count `json:"makers"`
+	Series []Count `json:"series"`
+	// CoStars are the other actresses ranked by the number of items they appear in with the actress
+	CoStars []Count `json:"co_stars"`
+}
+
+// NotFoundError reports an entity ID unknown to the API
+type NotFoundError struct {
+	Kind string
+	ID   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("graph: %s %s not found", e.Kind, e.ID)
+}
+
+// Items returns a page of the items of the actress, newest first.
+// offset starts from 1; hits is Graph.Hits if 0.
+func (g *Graph) Items(ctx context.Context, actressID string, offset, hits int) ([]dmm.Item, *dmm.Response, error) {
+	opt := g.itemOptions(actressID)
+	opt.Offset = offset
+	if hits > 0 {
+		opt.Hits = hits
+	}
+	return g.list(ctx, opt)
+}
+
+// Actress returns the profile of the actress, aggregating her newest items up to Graph.MaxItems.
+// A *NotFoundError is returned for an unknown actress ID.
+func (g *Graph) Actress(ctx context.Context, actressID string) (*ActressProfile, error) {
+	a, _, err := g.Client.Actresses.First(ctx, &dmm.ActressOptions{
+		APIID:       g.APIID,
+		AffiliateID: g.AffiliateID,
+		ActressID:   actressID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if a.ID != actressID {
+		return nil, &NotFoundError{Kind: "actress", ID: actressID}
+	}
+
+	p := &ActressProfile{Actress: a}
+	if p.Items, p.TotalItems, err = g.items(ctx, g.itemOptions(actressID)); err != nil {
+		return nil, err
+	}
+
+	p.Genres = g.rank(p.Items, dmm.ItemInfoGenre, "")
+	p.Makers = g.rank(p.Items, dmm.ItemInfoMaker, "")
+	p.Series = g.rank(p.Items, dmm.ItemInfoSeries, "")
+	p.CoStars = g.rank(p.Items, dmm.ItemInfoActress, actressID)
+	return p, nil
+}
+
+func (g *Graph) itemOptions(actressID string) *dmm.ItemOptions {
+	return g.articleOptions("actress", actressID, "", "", "")
+}
+
+// articleOptions returns the options of the first page of the items of the article, newest first
+func (g *Graph) articleOptions(article, id, site, service, floor string) *dmm.ItemOptions {
+	if site == "" {
+		site = g.Site
+	}
+	if site == "" {
+		site = dmm.SiteAdult
+	}
+	hits := g.Hits
+	if hits == 0 {
+		hits = DefaultHits
+	}
+	return &dmm.ItemOptions{
+		APIID:       g.APIID,
+		AffiliateID: g.AffiliateID,
+		Site:        site,
+		Service:     service,
+		Floor:       floor,
+		Sort:        "date",
+		Article:     article,
+		ArticleID:   id,
+		Hits:        hits,
+		Offset:      1,
+	}
+}
+
+// items pages through opt up to Graph.MaxItems, returning the items without duplicates and the total count
+func (g *Graph) items(ctx context.Context, opt *dmm.ItemOptions) (is []dmm.Item, total int, err error) {
+	seen := map[string]bool{}
+	err = g.Client.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+		page, r, err := g.list(ctx, opt)
+		if err != nil {
+			return r, err
+		}
+		total = r.TotalCount
+		for _, i := range page {
+			if len(is) >= g.maxItems() {
+				return nil, nil
+			}
+			if !seen[i.ContentID] {
+				seen[i.ContentID] = true
+				is = append(is, i)
+			}
+		}
+		return r, nil
+	})
+	return is, total, err
+}
+
+// list fetches a page of items, from the cache if there is one
+func (g *Graph) list(ctx context.Context, opt *dmm.ItemOptions) ([]dmm.Item, *dmm.Response, error) {
+	if g.Cache == nil {
+		return g.Client.Items.List(ctx, opt)
+	}
+	key := pageKey(*opt)
+	if p, ok := g.cachedPage(key); ok {
+		return p.Items, p.response(opt), nil
+	}
+	is, r, err := g.Client.Items.List(ctx, opt)
+	if err != nil {
+		return is, r, err
+	}
+	g.cachePage(key, newPage(is, r))
+	return is, r, nil
+}
+
+func (g *Graph) maxItems() int {
+	if g.MaxItems > 0 {
+		return g.MaxItems
+	}
+	return DefaultMaxItems
+}
+
+// rank counts the items each entity of the kind appears in, except the one with the ID exclude,
+// and returns the top entities, most items first
+func (g *Graph) rank(is []dmm.Item, kind, exclude string) []Count {
+	idx := map[string]int{}
+	var cs []Count
+	for _, i := range is {
+		counted := map[string]bool{}
+		for _, c := range i.Components(kind) {
+			id := c.ID.String()
+			if id == exclude || counted[id] {
+				continue
+			}
+			counted[id] = true
+			n, ok := idx[id]
+			if !ok {
+				n = len(cs)
+				idx[id] = n
+				cs = append(cs, Count{ID: id, Name: c.Name})
+			}
+			cs[n].Count++
+		}
+	}
+
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Count > cs[j].Count })
+	top := g.Top
+	if top == 0 {
+		top = DefaultTop
+	}
+	if len(cs) > top {
+		cs = cs[:top]
+	}
+	return cs
+}
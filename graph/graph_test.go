@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+func component(id, name string) dmm.ItemComponent {
+	return dmm.ItemComponent{ID: generic.MustString(id), Name: name}
+}
+
+// testItem returns an item of the actresses 1 and co, with the genres
+func testItem(n int, co dmm.ItemComponent, maker dmm.ItemComponent, genres ...dmm.ItemComponent) dmm.Item {
+	return dmm.Item{
+		ContentID: fmt.Sprintf("abc%05d", n),
+		Date:      fmt.Sprintf("2020-01-%02d 10:00:00", n),
+		ItemInfo: map[string][]dmm.ItemComponent{
+			dmm.ItemInfoActress: {
+				component("1", "actress 1"),
+				component("1_ruby", "あくとれす"),
+				co,
+			},
+			dmm.ItemInfoGenre:  genres,
+			dmm.ItemInfoMaker:  {maker},
+			dmm.ItemInfoSeries: {component("300", "series")},
+		},
+	}
+}
+
+func testServer() *dmmtest.Server {
+	s := dmmtest.NewServer()
+	s.Actresses = []dmm.Actress{{ID: "1", Name: "actress 1", Ruby: "あくとれす"}, {ID: "2", Name: "actress 2"}}
+	a2, a3 := component("2", "actress 2"), component("3", "actress 3")
+	m1, m2 := component("100", "maker 1"), component("101", "maker 2")
+	g1, g2 := component("200", "genre 1"), component("201", "genre 2")
+	s.Items = []dmm.Item{
+		testItem(1, a2, m1, g1),
+		testItem(2, a3, m1, g1, g2),
+		testItem(3, a2, m2, g2),
+		testItem(4, a2, m1, g1),
+		{ContentID: "other", ItemInfo: map[string][]dmm.ItemComponent{dmm.ItemInfoActress: {a2}}},
+	}
+	return s
+}
+
+func TestGraph_Actress(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	g := &Graph{Client: server.NewClient(), APIID: "api-1234", AffiliateID: "aff-990", Hits: 3}
+	p, err := g.Actress(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Graph.Actress returned error: %v", err)
+	}
+
+	if p.Actress.Name != "actress 1" || p.TotalItems != 4 || len(p.Items) != 4 {
+		t.Errorf("Graph.Actress returned %+v with %d items", p.Actress, len(p.Items))
+	}
+	if p.Items[0].ContentID != "abc00004" {
+		t.Errorf("Graph.Actress returned items from %s, expected the newest first", p.Items[0].ContentID)
+	}
+	expected := map[string][]Count{
+		"genres":   {{"200", "genre 1", 3}, {"201", "genre 2", 2}},
+		"makers":   {{"100", "maker 1", 3}, {"101", "maker 2", 1}},
+		"series":   {{"300", "series", 4}},
+		"co_stars": {{"2", "actress 2", 3}, {"3", "actress 3", 1}},
+	}
+	actual := map[string][]Count{"genres": p.Genres, "makers": p.Makers, "series": p.Series, "co_stars": p.CoStars}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Graph.Actress returned rankings %+v, expected %+v", actual, expected)
+	}
+}
+
+func TestGraph_Actress_limits(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	g := &Graph{Client: server.NewClient(), Hits: 3, MaxItems: 2, Top: 1}
+	p, err := g.Actress(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Graph.Actress returned error: %v", err)
+	}
+	if len(p.Items) != 2 || p.TotalItems != 4 {
+		t.Errorf("Graph.Actress returned %d of %d items, expected 2 of 4", len(p.Items), p.TotalItems)
+	}
+	if len(p.Genres) != 1 || len(p.CoStars) != 1 {
+		t.Errorf("Graph.Actress returned rankings longer than Top: %+v %+v", p.Genres, p.CoStars)
+	}
+	if n := server.RequestCount("ItemList"); n != 1 {
+		t.Errorf("Graph.Actress sent %d item requests, expected 1", n)
+	}
+}
+
+func TestGraph_Actress_notFound(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	g := &Graph{Client: server.NewClient()}
+	_, err := g.Actress(context.Background(), "999")
+	if nerr, ok := err.(*NotFoundError); !ok || nerr.ID != "999" {
+		t.Errorf("Graph.Actress returned %v, expected a *NotFoundError", err)
+	}
+}
+
+func TestGraph_Items(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	g := &Graph{Client: server.NewClient()}
+	is, r, err := g.Items(context.Background(), "1", 3, 2)
+	if err != nil {
+		t.Fatalf("Graph.Items returned error: %v", err)
+	}
+	if len(is) != 2 || is[0].ContentID != "abc00002" || r.TotalCount != 4 {
+		t.Errorf("Graph.Items returned %d items from %s of %d", len(is), is[0].ContentID, r.TotalCount)
+	}
+}
+
+func TestGraph_Items_cached(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	g := &Graph{Client: server.NewClient(), AffiliateID: "aff-990", Cache: dmm.NewMemoryCache()}
+	_, fetched, err := g.Items(context.Background(), "1", 3, 2)
+	if err != nil {
+		t.Fatalf("Graph.Items returned error: %v", err)
+	}
+	is, r, err := g.Items(context.Background(), "1", 3, 2)
+	if err != nil {
+		t.Fatalf("Graph.Items returned error: %v", err)
+	}
+	if n := server.RequestCount("ItemList"); n != 1 {
+		t.Errorf("Graph.Items sent %d requests, expected the page to be cached", n)
+	}
+	if len(is) != 2 || r.StatusCode != http.StatusOK || r.TotalCount != fetched.TotalCount || r.FirstPosition != fetched.FirstPosition {
+		t.Errorf("Graph.Items returned %d items and %+v from the cache", len(is), r)
+	}
+	if r.Parameters.GetHits() != 2 || r.Parameters.GetOffset() != 3 || r.IsLast() != fetched.IsLast() {
+		t.Errorf("Graph.Items returned the parameters %+v from the cache", r.Parameters)
+	}
+}
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/usk81/go-dmm"
+)
+
+// Summary aggregates the items of a maker or a series
+type Summary struct {
+	// Article is "maker" or "series"
+	Article string `json:"article"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+
+	// TotalItems is the number of items; the other fields aggregate at most Graph.MaxItems of them
+	TotalItems int `json:"total_items"`
+	Aggregated int `json:"aggregated"`
+	// FirstRelease and LastRelease are the dates of the oldest and newest aggregated items
+	FirstRelease string `json:"first_release"`
+	LastRelease  string `json:"last_release"`
+	// ReviewAverage is the average of the review averages weighted by their review counts
+	ReviewAverage float64 `json:"review_average"`
+	ReviewCount   int     `json:"review_count"`
+	// Actresses are ranked by the number of items they appear in
+	Actresses []Count `json:"actresses"`
+}
+
+// Maker returns the summary of the items of the maker in its floor
+func (g *Graph) Maker(ctx context.Context, m dmm.Maker) (*Summary, error) {
+	opt := g.articleOptions("maker", m.MakerID, m.SiteCode, m.ServiceCode, m.FloorCode)
+	return g.summarize(ctx, opt, m.Name)
+}
+
+// Series returns the summary of the items of the series in its floor
+func (g *Graph) Series(ctx context.Context, s dmm.Series) (*Summary, error) {
+	opt := g.articleOptions("series", s.SeriesID, s.SiteCode, s.ServiceCode, s.FloorCode)
+	return g.summarize(ctx, opt, s.Name)
+}
+
+func (g *Graph) summarize(ctx context.Context, opt *dmm.ItemOptions, name string) (*Summary, error) {
+	s := &Summary{Article: opt.Article, ID: opt.ArticleID, Name: name}
+	is, total, err := g.items(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	s.TotalItems, s.Aggregated = total, len(is)
+
+	var weighted float64
+	for _, i := range is {
+		if i.Date != "" && (s.FirstRelease == "" || i.Date < s.FirstRelease) {
+			s.FirstRelease = i.Date
+		}
+		if i.Date > s.LastRelease {
+			s.LastRelease = i.Date
+		}
+		if avg, err := strconv.ParseFloat(i.Review.Average, 64); err == nil && i.Review.Count > 0 {
+			weighted += avg * float64(i.Review.Count)
+			s.ReviewCount += i.Review.Count
+		}
+	}
+	if s.ReviewCount > 0 {
+		s.ReviewAverage = weighted / float64(s.ReviewCount)
+	}
+	s.Actresses = g.rank(is, dmm.ItemInfoActress, "")
+	return s, nil
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestGraph_Maker(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	for i := range server.Items {
+		server.Items[i].ServiceCode, server.Items[i].FloorCode = "digital", "videoa"
+	}
+	server.Items[0].Review = dmm.Review{Count: 1, Average: "5.00"}
+	server.Items[1].Review = dmm.Review{Count: 3, Average: "3.00"}
+
+	g := &Graph{Client: server.NewClient(), Hits: 2}
+	s, err := g.Maker(context.Background(), dmm.Maker{MakerID: "100", Name: "maker 1", SiteCode: dmm.SiteAdult, ServiceCode: "digital", FloorCode: "videoa"})
+	if err != nil {
+		t.Fatalf("Graph.Maker returned error: %v", err)
+	}
+
+	if s.Article != "maker" || s.ID != "100" || s.Name != "maker 1" || s.TotalItems != 3 || s.Aggregated != 3 {
+		t.Errorf("Graph.Maker returned %+v", s)
+	}
+	if s.FirstRelease != "2020-01-01 10:00:00" || s.LastRelease != "2020-01-04 10:00:00" {
+		t.Errorf("Graph.Maker returned releases %s - %s", s.FirstRelease, s.LastRelease)
+	}
+	if s.ReviewCount != 4 || math.Abs(s.ReviewAverage-3.5) > 1e-9 {
+		t.Errorf("Graph.Maker returned review average %v of %d", s.ReviewAverage, s.ReviewCount)
+	}
+	expected := []Count{{"1", "actress 1", 3}, {"2", "actress 2", 2}, {"3", "actress 3", 1}}
+	if !reflect.DeepEqual(s.Actresses, expected) {
+		t.Errorf("Graph.Maker returned actresses %+v, expected %+v", s.Actresses, expected)
+	}
+}
+
+func TestGraph_Series_cache(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	cache := &dmm.MemoryCache{}
+	g := &Graph{Client: server.NewClient(), APIID: "api-1234", Hits: 3, Cache: cache}
+	series := dmm.Series{SeriesID: "300", Name: "series"}
+	first, err := g.Series(context.Background(), series)
+	if err != nil {
+		t.Fatalf("Graph.Series returned error: %v", err)
+	}
+	if first.TotalItems != 4 || first.Aggregated != 4 {
+		t.Errorf("Graph.Series returned %+v", first)
+	}
+	requests := server.RequestCount("ItemList")
+	if requests != 2 || cache.Len() != 2 {
+		t.Errorf("Graph.Series sent %d requests and cached %d pages, expected 2 and 2", requests, cache.Len())
+	}
+
+	g.APIID = "other"
+	second, err := g.Series(context.Background(), series)
+	if err != nil {
+		t.Fatalf("Graph.Series returned error: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("cached Graph.Series returned %+v, expected %+v", second, first)
+	}
+	if n := server.RequestCount("ItemList"); n != requests {
+		t.Errorf("cached Graph.Series sent %d requests", n-requests)
+	}
+}
@@ -0,0 +1,119 @@
+package dmm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// contentIDPattern matches content IDs like juy00553, 1hawa00124, h_237nacr00123 and juy553so
+var contentIDPattern = regexp.MustCompile(`^((?:h_)?[0-9]*)([a-z]+)([0-9]+)([a-z]*)$`)
+
+// productCodePattern matches product codes like JUY-553, ABP-001R and labels with digits like 300MIUM-001 and T28-557
+var productCodePattern = regexp.MustCompile(`^([a-z0-9]*[a-z][a-z0-9]*)[-_ ]([0-9]+)[-_]?([a-z]*)$`)
+
+// compactCodePattern matches product codes without a separator like JUY553,
+// splitting the label from the number at the last letters-then-digits boundary
+var compactCodePattern = regexp.MustCompile(`^([a-z0-9]*[a-z])([0-9]+)([a-z]*)$`)
+
+// Identifier is a product identified by its label and number,
+// which content IDs, product IDs and maker product codes spell differently
+type Identifier struct {
+	// Prefix is the maker prefix of a content ID, e.g. "1" of 1hawa00124 or "h_237" of h_237nacr00123
+	Prefix string
+	// Label is the lower-cased label, e.g. "juy"
+	Label string
+	// Number is the product number within the label
+	Number int
+	// Suffix is the lower-cased suffix, e.g. "so" of juy553so
+	Suffix string
+}
+
+// ParseIdentifier parses a content ID (juy00553), product ID or maker product code (JUY-553)
+func ParseIdentifier(s string) (Identifier, error) {
+	t := strings.ToLower(strings.TrimSpace(s))
+	if m := contentIDPattern.FindStringSubmatch(t); m != nil {
+		n, err := strconv.Atoi(m[3])
+		if err != nil {
+			return Identifier{}, err
+		}
+		return Identifier{Prefix: m[1], Label: m[2], Number: n, Suffix: m[4]}, nil
+	}
+	for _, p := range []*regexp.Regexp{productCodePattern, compactCodePattern} {
+		if m := p.FindStringSubmatch(t); m != nil {
+			n, err := strconv.Atoi(m[2])
+			if err != nil {
+				return Identifier{}, err
+			}
+			return Identifier{Label: m[1], Number: n, Suffix: m[3]}, nil
+		}
+	}
+	return Identifier{}, fmt.Errorf("dmm: %q is not a content ID or product code", s)
+}
+
+// ContentID returns the content ID of digital items, with the number padded to five digits, e.g. juy00553
+func (id Identifier) ContentID() string {
+	return fmt.Sprintf("%s%s%05d%s", id.Prefix, id.Label, id.Number, id.Suffix)
+}
+
+// ShortContentID returns the content ID of physical items, with the number unpadded, e.g. juy553
+func (id Identifier) ShortContentID() string {
+	return fmt.Sprintf("%s%s%d%s", id.Prefix, id.Label, id.Number, id.Suffix)
+}
+
+// ProductCode returns the maker product code, with the number padded to three digits, e.g. JUY-553
+func (id Identifier) ProductCode() string {
+	return strings.ToUpper(fmt.Sprintf("%s-%03d%s", id.Label, id.Number, id.Suffix))
+}
+
+// String returns the product code
+func (id Identifier) String() string {
+	return id.ProductCode()
+}
+
+// Same reports whether id and o identify the same product, ignoring the maker prefix and the suffix.
+// A prefix spelled as part of the label of a product code, e.g. 300MIUM-001 for 300mium001, matches too.
+func (id Identifier) Same(o Identifier) bool {
+	if id.Number != o.Number {
+		return false
+	}
+	return id.Label == o.Label || id.Prefix+id.Label == o.Prefix+o.Label
+}
+
+// Identifier returns the identifier of the item, parsed from its content ID or else its maker product code
+func (i Item) Identifier() (Identifier, error) {
+	id, err := ParseIdentifier(i.ContentID)
+	if err != nil && i.MakerProduct != "" {
+		return ParseIdentifier(i.MakerProduct)
+	}
+	return id, err
+}
+
+// contentIDs returns the content IDs the product may have, starting with s if it is one
+func (id Identifier) contentIDs(s string) []string {
+	var cids []string
+	seen := map[string]bool{}
+	add := func(cid string) {
+		if !seen[cid] {
+			seen[cid] = true
+			cids = append(cids, cid)
+		}
+	}
+	if t := strings.ToLower(strings.TrimSpace(s)); contentIDPattern.MatchString(t) {
+		add(t)
+	}
+	add(id.ContentID())
+	add(id.ShortContentID())
+	return cids
+}
+
+// identifiedBy reports whether the content ID or the maker product code of the item identifies the same product as id
+func (i Item) identifiedBy(id Identifier) bool {
+	for _, s := range []string{i.ContentID, i.MakerProduct} {
+		if iid, err := ParseIdentifier(s); err == nil && iid.Same(id) {
+			return true
+		}
+	}
+	return false
+}
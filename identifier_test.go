@@ -0,0 +1,90 @@
+package dmm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIdentifier(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected Identifier
+	}{
+		{"juy00553", Identifier{Label: "juy", Number: 553}},
+		{"juy553", Identifier{Label: "juy", Number: 553}},
+		{"1hawa00124", Identifier{Prefix: "1", Label: "hawa", Number: 124}},
+		{"h_237nacr00123", Identifier{Prefix: "h_237", Label: "nacr", Number: 123}},
+		{"juy553so", Identifier{Label: "juy", Number: 553, Suffix: "so"}},
+		{"JUY-553", Identifier{Label: "juy", Number: 553}},
+		{" abp-001r ", Identifier{Label: "abp", Number: 1, Suffix: "r"}},
+		{"HAWA_124", Identifier{Label: "hawa", Number: 124}},
+		{"JUY553", Identifier{Label: "juy", Number: 553}},
+		{"300mium001", Identifier{Prefix: "300", Label: "mium", Number: 1}},
+		{"300MIUM-001", Identifier{Label: "300mium", Number: 1}},
+		{"T28-557", Identifier{Label: "t28", Number: 557}},
+		{"t28_557z", Identifier{Label: "t28", Number: 557, Suffix: "z"}},
+		{"ab12cd34", Identifier{Label: "ab12cd", Number: 34}},
+	}
+	for _, c := range cases {
+		actual, err := ParseIdentifier(c.in)
+		if err != nil {
+			t.Errorf("ParseIdentifier(%q) returned error: %v", c.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("ParseIdentifier(%q) returned %+v, expected %+v", c.in, actual, c.expected)
+		}
+	}
+
+	for _, in := range []string{"", "553", "juy", "JUY-553-554", "ジュイ553"} {
+		if _, err := ParseIdentifier(in); err == nil {
+			t.Errorf("ParseIdentifier(%q) returned no error", in)
+		}
+	}
+}
+
+func TestIdentifier_forms(t *testing.T) {
+	id := Identifier{Prefix: "1", Label: "hawa", Number: 124}
+	if s := id.ContentID(); s != "1hawa00124" {
+		t.Errorf("Identifier.ContentID returned %q", s)
+	}
+	if s := id.ShortContentID(); s != "1hawa124" {
+		t.Errorf("Identifier.ShortContentID returned %q", s)
+	}
+	if s := id.ProductCode(); s != "HAWA-124" {
+		t.Errorf("Identifier.ProductCode returned %q", s)
+	}
+	if s := (Identifier{Label: "abp", Number: 1, Suffix: "r"}).String(); s != "ABP-001R" {
+		t.Errorf("Identifier.String returned %q", s)
+	}
+	if !id.Same(Identifier{Label: "hawa", Number: 124, Suffix: "so"}) || id.Same(Identifier{Label: "hawa", Number: 125}) {
+		t.Error("Identifier.Same compared wrongly")
+	}
+	cid, _ := ParseIdentifier("300mium001")
+	code, _ := ParseIdentifier("300MIUM-001")
+	if !cid.Same(code) || !code.Same(cid) {
+		t.Errorf("Identifier.Same of %+v and %+v returned false", cid, code)
+	}
+}
+
+func TestIdentifier_contentIDs(t *testing.T) {
+	id, _ := ParseIdentifier("JUY-553")
+	if cids := id.contentIDs("JUY-553"); !reflect.DeepEqual(cids, []string{"juy00553", "juy553"}) {
+		t.Errorf("Identifier.contentIDs returned %v", cids)
+	}
+	id, _ = ParseIdentifier("juy553so")
+	if cids := id.contentIDs("juy553so"); !reflect.DeepEqual(cids, []string{"juy553so", "juy00553so"}) {
+		t.Errorf("Identifier.contentIDs returned %v", cids)
+	}
+}
+
+func TestItem_Identifier(t *testing.T) {
+	id, err := Item{ContentID: "1hawa00124"}.Identifier()
+	if err != nil || id.ProductCode() != "HAWA-124" {
+		t.Errorf("Item.Identifier returned %+v, %v", id, err)
+	}
+	id, err = Item{ContentID: "ｘ", MakerProduct: "JUY-553"}.Identifier()
+	if err != nil || id.ProductCode() != "JUY-553" {
+		t.Errorf("Item.Identifier returned %+v, %v", id, err)
+	}
+}
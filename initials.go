@@ -0,0 +1,134 @@
+package dmm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/usk81/go-dmm/kana"
+)
+
+// DefaultIndexConcurrency is the number of initials fetched at once by the Index functions if no concurrency is given
+const DefaultIndexConcurrency = 4
+
+// indexHits is the page size of the lists fetched by the Index functions
+const indexHits = 100
+
+// InitialError reports an Initial option that is not a kana of the gojūon table
+type InitialError struct {
+	Initial string
+}
+
+func (e *InitialError) Error() string {
+	return fmt.Sprintf("dmm: initial %q is not a kana of the gojūon table", e.Initial)
+}
+
+// Initials returns the initials accepted by the Initial options, in gojūon order
+func Initials() []string {
+	return strings.Split(kana.Gojuon, "")
+}
+
+// NormalizeInitial returns the hiragana initial for s, which may also be in katakana or half width katakana.
+// An *InitialError is returned if s is not a single kana of the gojūon table.
+func NormalizeInitial(s string) (string, error) {
+	i := kana.ToHiragana(kana.FoldWidth(s))
+	if !kana.IsGojuon(i) {
+		return "", &InitialError{Initial: s}
+	}
+	return i, nil
+}
+
+// indexInitials returns the initials to index: all of them, or the initial of the options if it is set
+func indexInitials(initial string) ([]string, error) {
+	if initial == "" {
+		return Initials(), nil
+	}
+	i, err := NormalizeInitial(initial)
+	if err != nil {
+		return nil, err
+	}
+	return []string{i}, nil
+}
+
+// indexPage sets the page of the first request for an initial
+func indexPage(hits, offset *int) {
+	if *hits == 0 {
+		*hits = indexHits
+	}
+	*offset = 1
+}
+
+// fetchIndex fetches the list of every initial to index with fetch, running at most concurrency fetches at once.
+// It returns the indexed initials in gojūon order and the lists by initial.
+func fetchIndex(ctx context.Context, initial string, concurrency int, fetch func(ctx context.Context, initial string) (interface{}, error)) ([]string, map[string]interface{}, error) {
+	initials, err := indexInitials(initial)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mu sync.Mutex
+	lists := make(map[string]interface{}, len(initials))
+	err = eachInitial(ctx, initials, concurrency, func(ctx context.Context, initial string) error {
+		l, err := fetch(ctx, initial)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		lists[initial] = l
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return initials, lists, nil
+}
+
+// eachInitial calls fetch for every initial, running at most concurrency calls at once.
+// The first error cancels the calls still running and is returned.
+func eachInitial(ctx context.Context, initials []string, concurrency int, fetch func(ctx context.Context, initial string) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultIndexConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+		sem   = make(chan struct{}, concurrency)
+	)
+	for _, initial := range initials {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(initial string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fetch(ctx, initial); err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}(initial)
+	}
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,126 @@
+package dmm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInitials(t *testing.T) {
+	is := Initials()
+	if len(is) != 46 || is[0] != "あ" || is[45] != "ん" {
+		t.Errorf("Initials returned %v", is)
+	}
+}
+
+func TestNormalizeInitial(t *testing.T) {
+	cases := map[string]string{
+		"あ": "あ",
+		"カ": "か",
+		"ｻ": "さ",
+	}
+	for in, expected := range cases {
+		if got, err := NormalizeInitial(in); got != expected || err != nil {
+			t.Errorf("NormalizeInitial(%q) returned %q, %v, expected %q", in, got, err, expected)
+		}
+	}
+
+	for _, in := range []string{"", "a", "が", "あい", "漢"} {
+		_, err := NormalizeInitial(in)
+		if ierr, ok := err.(*InitialError); !ok || ierr.Initial != in {
+			t.Errorf("NormalizeInitial(%q) returned %v, expected an *InitialError", in, err)
+		}
+	}
+}
+
+func TestEachInitial(t *testing.T) {
+	var mu sync.Mutex
+	running, max := 0, 0
+	var fetched []string
+	err := eachInitial(ctx, Initials(), 3, func(ctx context.Context, initial string) error {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		fetched = append(fetched, initial)
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("eachInitial returned error: %v", err)
+	}
+	if len(fetched) != 46 {
+		t.Errorf("eachInitial fetched %d initials, expected 46", len(fetched))
+	}
+	if max > 3 {
+		t.Errorf("eachInitial ran %d fetches at once, expected at most 3", max)
+	}
+}
+
+func TestEachInitial_error(t *testing.T) {
+	expected := errors.New("failed")
+	err := eachInitial(ctx, Initials(), 2, func(ctx context.Context, initial string) error {
+		if initial == "い" {
+			return expected
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != expected {
+		t.Errorf("eachInitial returned %v, expected %v", err, expected)
+	}
+}
+
+func TestIndexGenres(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+genreBasePath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		initial := r.URL.Query().Get("initial")
+		var genres string
+		var n int
+		switch initial {
+		case "あ":
+			genres, n = `{"genre_id":"1","name":"アクション","ruby":"あくしょん"},{"genre_id":"2","name":"アニメ","ruby":"あにめ"}`, 2
+		case "か":
+			genres, n = `{"genre_id":"3","name":"カップル","ruby":"かっぷる"}`, 1
+		}
+		fmt.Fprintf(w, `{"request":{"parameters":{"floor_id":"43","initial":%q}},"result":{"status":"200","result_count":%d,"total_count":"%d","first_position":1,"genre":[%s]}}`,
+			initial, n, n, genres)
+	})
+
+	idx, err := IndexGenres(ctx, client, &GenreOptions{FloorID: "43"}, 0)
+	if err != nil {
+		t.Fatalf("IndexGenres returned error: %v", err)
+	}
+	if !reflect.DeepEqual(idx.Initials, Initials()) {
+		t.Errorf("IndexGenres returned initials %v", idx.Initials)
+	}
+	if len(idx.Genres["あ"]) != 2 || len(idx.Genres["か"]) != 1 || len(idx.Genres["さ"]) != 0 {
+		t.Errorf("IndexGenres returned %+v", idx.Genres)
+	}
+
+	idx, err = IndexGenres(ctx, client, &GenreOptions{FloorID: "43", Initial: "カ"}, 0)
+	if err != nil {
+		t.Fatalf("IndexGenres returned error: %v", err)
+	}
+	if !reflect.DeepEqual(idx.Initials, []string{"か"}) || idx.Genres["か"][0].GenreID != "3" {
+		t.Errorf("IndexGenres returned %+v", idx)
+	}
+
+	if _, err := IndexGenres(ctx, client, &GenreOptions{FloorID: "43", Initial: "x"}, 0); err == nil {
+		t.Error("IndexGenres with an invalid initial returned no error")
+	}
+}
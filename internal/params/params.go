@@ -0,0 +1,36 @@
+// Package params reflects on the options structs of the dmm package,
+// for front-ends taking API parameters from users.
+package params
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Reserved are the API parameters users can not set
+var Reserved = map[string]bool{
+	"api_id":       true,
+	"affiliate_id": true,
+	"output":       true,
+	"callback":     true,
+}
+
+// Name returns the API parameter name of a field of an options struct, or "" if it is not a parameter
+func Name(f reflect.StructField) string {
+	tag := f.Tag.Get("url")
+	if tag == "" || tag == "-" {
+		return ""
+	}
+	return strings.Split(tag, ",")[0]
+}
+
+// SetCredentials sets the APIID and AffiliateID fields of opt, a pointer to an options struct
+func SetCredentials(opt interface{}, apiID, affiliateID string) {
+	v := reflect.ValueOf(opt).Elem()
+	if f := v.FieldByName("APIID"); f.IsValid() {
+		f.SetString(apiID)
+	}
+	if f := v.FieldByName("AffiliateID"); f.IsValid() {
+		f.SetString(affiliateID)
+	}
+}
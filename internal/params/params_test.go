@@ -0,0 +1,34 @@
+package params
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/usk81/go-dmm"
+)
+
+func TestName(t *testing.T) {
+	typ := reflect.TypeOf(dmm.ItemOptions{})
+	cases := map[string]string{
+		"APIID":   "api_id",
+		"GteDate": "gte_date",
+		"Hits":    "hits",
+	}
+	for field, expected := range cases {
+		f, _ := typ.FieldByName(field)
+		if name := Name(f); name != expected {
+			t.Errorf("Name(%s) returned %q, expected %q", field, name, expected)
+		}
+	}
+	if name := Name(reflect.StructField{Name: "Other"}); name != "" {
+		t.Errorf("Name of an untagged field returned %q", name)
+	}
+}
+
+func TestSetCredentials(t *testing.T) {
+	opt := &dmm.FloorOptions{}
+	SetCredentials(opt, "api-1234", "aff-990")
+	if opt.APIID != "api-1234" || opt.AffiliateID != "aff-990" {
+		t.Errorf("SetCredentials set %+v", opt)
+	}
+}
@@ -0,0 +1,227 @@
+// Package itemdiff compares two snapshots of items, e.g. two crawls of a floor,
+// and reports the added and removed items and the field level changes of the others.
+//
+//	r := itemdiff.Diff(yesterday, today)
+//	err := r.WriteJSON(os.Stdout)
+package itemdiff
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/usk81/go-dmm"
+)
+
+// Compared fields
+const (
+	FieldTitle         = "title"
+	FieldDate          = "date"
+	FieldPrice         = "price"
+	FieldListPrice     = "list_price"
+	FieldDeliveries    = "deliveries"
+	FieldStock         = "stock"
+	FieldReviewCount   = "review_count"
+	FieldReviewAverage = "review_average"
+)
+
+// iteminfoKinds are the ItemInfo keys whose membership is compared
+var iteminfoKinds = []string{
+	dmm.ItemInfoActress,
+	dmm.ItemInfoAuthor,
+	dmm.ItemInfoDirector,
+	dmm.ItemInfoGenre,
+	dmm.ItemInfoLabel,
+	dmm.ItemInfoMaker,
+	dmm.ItemInfoSeries,
+}
+
+// Report is the difference between two snapshots
+type Report struct {
+	Added   []Summary `json:"added"`
+	Removed []Summary `json:"removed"`
+	Changed []Change  `json:"changed"`
+}
+
+// Summary identifies an added or removed item
+type Summary struct {
+	ContentID string `json:"content_id"`
+	Title     string `json:"title"`
+	Date      string `json:"date,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// Change lists the changed fields of an item present in both snapshots
+type Change struct {
+	ContentID string        `json:"content_id"`
+	Title     string        `json:"title"`
+	Fields    []FieldChange `json:"fields"`
+}
+
+// FieldChange is the change of a single field.
+// Scalar fields set Old and New; set valued fields (deliveries and iteminfo.*) set Added and Removed.
+// The price change of a delivery available in both snapshots is the scalar field deliveries.<type>.
+type FieldChange struct {
+	Field   string   `json:"field"`
+	Old     string   `json:"old,omitempty"`
+	New     string   `json:"new,omitempty"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Diff compares the before and after snapshots, keyed by ContentID.
+// If a snapshot holds an item more than once, the last one is used.
+func Diff(before, after []dmm.Item) Report {
+	om, nm := index(before), index(after)
+	r := Report{Added: []Summary{}, Removed: []Summary{}, Changed: []Change{}}
+
+	for _, cid := range sortedKeys(nm) {
+		n := nm[cid]
+		o, ok := om[cid]
+		if !ok {
+			r.Added = append(r.Added, summarize(n))
+			continue
+		}
+		if fs := CompareItems(o, n); len(fs) > 0 {
+			r.Changed = append(r.Changed, Change{ContentID: cid, Title: n.Title, Fields: fs})
+		}
+	}
+	for _, cid := range sortedKeys(om) {
+		if _, ok := nm[cid]; !ok {
+			r.Removed = append(r.Removed, summarize(om[cid]))
+		}
+	}
+	return r
+}
+
+// Empty reports whether nothing changed
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// WriteJSON writes the report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// CompareItems returns the changed fields from o to n
+func CompareItems(o, n dmm.Item) []FieldChange {
+	var fs []FieldChange
+	scalar := func(field, from, to string) {
+		if from != to {
+			fs = append(fs, FieldChange{Field: field, Old: from, New: to})
+		}
+	}
+	set := func(field string, from, to []string) {
+		added, removed := setDiff(from, to)
+		if len(added) > 0 || len(removed) > 0 {
+			fs = append(fs, FieldChange{Field: field, Added: added, Removed: removed})
+		}
+	}
+
+	scalar(FieldTitle, o.Title, n.Title)
+	scalar(FieldDate, o.Date, n.Date)
+	scalar(FieldPrice, o.Prices.Price, n.Prices.Price)
+	scalar(FieldListPrice, o.Prices.ListPrice, n.Prices.ListPrice)
+	od, nd := deliveries(o), deliveries(n)
+	set(FieldDeliveries, deliveryTypes(od, nd), deliveryTypes(nd, od))
+	for _, typ := range sortedTypes(nd) {
+		if p, ok := od[typ]; ok {
+			scalar(FieldDeliveries+"."+typ, p, nd[typ])
+		}
+	}
+	scalar(FieldStock, o.Stock, n.Stock)
+	scalar(FieldReviewCount, strconv.Itoa(o.Review.Count), strconv.Itoa(n.Review.Count))
+	scalar(FieldReviewAverage, o.Review.Average, n.Review.Average)
+	for _, k := range iteminfoKinds {
+		set("iteminfo."+k, components(o, k), components(n, k))
+	}
+	return fs
+}
+
+func index(is []dmm.Item) map[string]dmm.Item {
+	m := make(map[string]dmm.Item, len(is))
+	for _, i := range is {
+		m[i.ContentID] = i
+	}
+	return m
+}
+
+func sortedKeys(m map[string]dmm.Item) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+func summarize(i dmm.Item) Summary {
+	return Summary{ContentID: i.ContentID, Title: i.Title, Date: i.Date, URL: i.URL}
+}
+
+// deliveries returns the delivery prices by type
+func deliveries(i dmm.Item) map[string]string {
+	ds := make(map[string]string, len(i.Prices.Deliveries.Delivery))
+	for _, d := range i.Prices.Deliveries.Delivery {
+		ds[d.Type] = d.Price
+	}
+	return ds
+}
+
+// deliveryTypes returns the deliveries of ds as "type:price", except those of types also in other.
+// A delivery in both snapshots is compared by price instead.
+func deliveryTypes(ds, other map[string]string) []string {
+	var vs []string
+	for _, typ := range sortedTypes(ds) {
+		if _, ok := other[typ]; !ok {
+			vs = append(vs, typ+":"+ds[typ])
+		}
+	}
+	return vs
+}
+
+func sortedTypes(ds map[string]string) []string {
+	ts := make([]string, 0, len(ds))
+	for t := range ds {
+		ts = append(ts, t)
+	}
+	sort.Strings(ts)
+	return ts
+}
+
+// components returns the item information of kind as "name (id)"
+func components(i dmm.Item, kind string) []string {
+	var cs []string
+	for _, c := range i.Components(kind) {
+		cs = append(cs, c.Name+" ("+c.ID.String()+")")
+	}
+	return cs
+}
+
+// setDiff returns the sorted values only in to and only in from
+func setDiff(from, to []string) (added, removed []string) {
+	om := make(map[string]bool, len(from))
+	for _, v := range from {
+		om[v] = true
+	}
+	nm := make(map[string]bool, len(to))
+	for _, v := range to {
+		nm[v] = true
+		if !om[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range from {
+		if !nm[v] {
+			removed = append(removed, v)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -0,0 +1,95 @@
+package itemdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+)
+
+func TestDiff(t *testing.T) {
+	before := []dmm.Item{
+		{ContentID: "a", Title: "A"},
+		{ContentID: "b", Title: "B", Prices: dmm.Prices{Price: "1000", Deliveries: dmm.Deliveries{Delivery: []dmm.Delivery{
+			{Type: "stream", Price: "300"},
+			{Type: "hd", Price: "500"},
+		}}}, Review: dmm.Review{Count: 1, Average: "5.00"}, ItemInfo: map[string][]dmm.ItemComponent{
+			dmm.ItemInfoActress: {
+				{ID: generic.MustString(1), Name: "X"},
+				{ID: generic.MustString("1_ruby"), Name: "えっくす"},
+			},
+		}},
+		{ContentID: "c", Title: "C"},
+	}
+	after := []dmm.Item{
+		{ContentID: "b", Title: "B2", Prices: dmm.Prices{Price: "800", Deliveries: dmm.Deliveries{Delivery: []dmm.Delivery{
+			{Type: "stream", Price: "250"},
+			{Type: "download", Price: "980"},
+		}}}, Review: dmm.Review{Count: 2, Average: "4.50"}, ItemInfo: map[string][]dmm.ItemComponent{
+			dmm.ItemInfoActress: {
+				{ID: generic.MustString(2), Name: "Y"},
+			},
+		}},
+		{ContentID: "c", Title: "C"},
+		{ContentID: "d", Title: "D", Date: "2020-01-01 10:00:00"},
+	}
+
+	r := Diff(before, after)
+	expected := Report{
+		Added:   []Summary{{ContentID: "d", Title: "D", Date: "2020-01-01 10:00:00"}},
+		Removed: []Summary{{ContentID: "a", Title: "A"}},
+		Changed: []Change{{
+			ContentID: "b",
+			Title:     "B2",
+			Fields: []FieldChange{
+				{Field: FieldTitle, Old: "B", New: "B2"},
+				{Field: FieldPrice, Old: "1000", New: "800"},
+				{Field: FieldDeliveries, Added: []string{"download:980"}, Removed: []string{"hd:500"}},
+				{Field: "deliveries.stream", Old: "300", New: "250"},
+				{Field: FieldReviewCount, Old: "1", New: "2"},
+				{Field: FieldReviewAverage, Old: "5.00", New: "4.50"},
+				{Field: "iteminfo.actress", Added: []string{"Y (2)"}, Removed: []string{"X (1)"}},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("Diff returned %+v, expected %+v", r, expected)
+	}
+	if r.Empty() {
+		t.Error("Report.Empty returned true")
+	}
+}
+
+func TestDiff_unchanged(t *testing.T) {
+	is := []dmm.Item{{ContentID: "a", Title: "A"}}
+	if r := Diff(is, is); !r.Empty() {
+		t.Errorf("Diff returned %+v, expected an empty report", r)
+	}
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	r := Diff([]dmm.Item{{ContentID: "a", Title: "A&B"}}, nil)
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("Report.WriteJSON returned error: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Report.WriteJSON wrote invalid JSON: %v", err)
+	}
+	expected := map[string]interface{}{
+		"added":   []interface{}{},
+		"removed": []interface{}{map[string]interface{}{"content_id": "a", "title": "A&B"}},
+		"changed": []interface{}{},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Report.WriteJSON wrote %s", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("A&B")) {
+		t.Errorf("HTML characters were escaped: %s", buf.String())
+	}
+}
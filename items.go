@@ -3,13 +3,27 @@ package dmm
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/usk81/generic/v2"
 )
 
 const itemBasePath = `affiliate/v3/ItemList`
 
+// lookupHits is the page size of the keyword search of Lookup
+const lookupHits = 100
+
+// DefaultGetManyConcurrency is the number of requests GetMany sends at once by default
+const DefaultGetManyConcurrency = 4
+
+// ErrItemNotFound is returned by Lookup, and reported by GetMany, if no item has the identifier
+var ErrItemNotFound = errors.New("dmm: item not found")
+
 // ItemsService is an interface for interfacing with the Item
 // endpoints of the DMM Affiliate API
 // See: https://affiliate.dmm.com/api/v3/itemlist.html
@@ -90,12 +104,36 @@ type ImageURL struct {
 // 	Series   []ItemComponent `json:"series"`
 // }
 
+// ItemInfo keys
+const (
+	ItemInfoActress  = "actress"
+	ItemInfoAuthor   = "author"
+	ItemInfoDirector = "director"
+	ItemInfoGenre    = "genre"
+	ItemInfoLabel    = "label"
+	ItemInfoMaker    = "maker"
+	ItemInfoSeries   = "series"
+)
+
 // ItemComponent is a product detail
 type ItemComponent struct {
 	ID   generic.String `json:"id"`
 	Name string         `json:"name"`
 }
 
+// Components returns the item information for key, e.g. ItemInfoActress.
+// Supplementary entries like readings ("1046150_ruby") and classifications ("1046150_classify") are left out.
+func (i Item) Components(key string) []ItemComponent {
+	var cs []ItemComponent
+	for _, c := range i.ItemInfo[key] {
+		if strings.Contains(c.ID.String(), "_") {
+			continue
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
 // Prices is a price information
 type Prices struct {
 	Price      string     `json:"price"`
@@ -114,6 +152,21 @@ type Delivery struct {
 	Price string `json:"price"`
 }
 
+// ParsePrice parses prices like "2381", "1,980" or "300~" in yen.
+// from reports whether the price is a lower bound, marked by "~", "～" or "〜".
+func ParsePrice(s string) (yen int, from bool, err error) {
+	s = strings.TrimSpace(s)
+	t := strings.TrimRight(s, "~～〜")
+	yen, err = strconv.Atoi(strings.Replace(t, ",", "", -1))
+	if err != nil {
+		return 0, false, err
+	}
+	if yen < 0 {
+		return 0, false, fmt.Errorf("dmm: negative price %q", s)
+	}
+	return yen, t != s, nil
+}
+
 // Review is a review for a product
 type Review struct {
 	Count   int    `json:"count"`
@@ -192,20 +245,21 @@ type internalItemOptions struct {
 }
 
 func (r *itemRoot) populatePageValues(res *Response) {
+	res.ResultStatus = r.Result.Status.Int()
 	res.FirstPosition = r.Result.FirstPosition.Int()
 	res.ResultCount = r.Result.ResultCount.Int()
 	res.TotalCount = r.Result.TotalCount.Int()
 	res.Parameters = r.Request.Parameters.Convert()
 }
 
-func (s *ItemsServiceOp) list(ctx context.Context, path string) (itemResult, *Response, error) {
+func (s *ItemsServiceOp) list(ctx context.Context, path string, opt ListOptions) (itemResult, *Response, error) {
 	req, err := s.client.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return itemResult{}, nil, err
 	}
 
 	var root itemRoot
-	resp, err := s.client.Do(ctx, req, &root)
+	resp, err := s.client.do(ctx, &Call{Request: req, Options: listOptions(opt), Value: &root})
 	if err != nil {
 		return itemResult{}, resp, err
 	}
@@ -237,7 +291,7 @@ func (s *ItemsServiceOp) Unmarshal(ctx context.Context, opt *ItemOptions, out in
 	}
 	var res itemResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return r, err
 	}
@@ -250,6 +304,145 @@ func (s *ItemsServiceOp) Unmarshal(ctx context.Context, opt *ItemOptions, out in
 	return r, err
 }
 
+// Lookup returns the item identified by id, a content ID, product ID or maker product code,
+// among the items of opt's site, service and floor.
+// The content IDs the identifier may have are requested first; if there is no such item,
+// the items found with the product code as keyword are compared by their identifiers.
+// ErrItemNotFound is returned if no item matches.
+func Lookup(ctx context.Context, c *Client, opt *ItemOptions, id string) (Item, *Response, error) {
+	ident, err := ParseIdentifier(id)
+	if err != nil {
+		return Item{}, nil, err
+	}
+	var o ItemOptions
+	if opt != nil {
+		o = *opt
+	}
+
+	for _, cid := range ident.contentIDs(id) {
+		co := o
+		co.ContentID = cid
+		i, r, err := c.Items.First(ctx, &co)
+		if err != nil {
+			return Item{}, r, err
+		}
+		if i.ContentID != "" {
+			return i, r, nil
+		}
+	}
+
+	ko := o
+	ko.Keyword, ko.Hits, ko.Offset = ident.ProductCode(), lookupHits, 1
+	is, r, err := c.Items.List(ctx, &ko)
+	if err != nil {
+		return Item{}, r, err
+	}
+	for _, i := range is {
+		if i.identifiedBy(ident) {
+			return i, r, nil
+		}
+	}
+	return Item{}, r, ErrItemNotFound
+}
+
+// ItemResult is the outcome of fetching one content ID with GetMany
+type ItemResult struct {
+	Item Item
+	Err  error
+}
+
+// GetManyOptions specifies the optional parameters to GetMany
+type GetManyOptions struct {
+	// ItemOptions are the credentials, site, service and floor of the items.
+	// Its other parameters are ignored.
+	ItemOptions
+	// Concurrency is the number of requests sent at once, DefaultGetManyConcurrency if 0
+	Concurrency int
+}
+
+// GetMany fetches the items with the given content IDs among the items of opt's site,
+// service and floor, running at most opt.Concurrency requests at once.
+// The IDs are trimmed of spaces, and empty and duplicate IDs are skipped. The result has an entry
+// for every other ID, keyed by the trimmed ID; its Err is ErrItemNotFound if there is no such item.
+// Requests pass through the client's middleware, so cached items are not requested again
+// and a rate limiter is honoured.
+// If ctx is done before every ID is fetched, the remaining entries carry the context's error,
+// which is returned as well.
+func GetMany(ctx context.Context, c *Client, cids []string, opt *GetManyOptions) (map[string]ItemResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var o GetManyOptions
+	if opt != nil {
+		o = *opt
+	}
+	concurrency := o.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultGetManyConcurrency
+	}
+	base := ItemOptions{
+		APIID:       o.APIID,
+		AffiliateID: o.AffiliateID,
+		Site:        o.Site,
+		Service:     o.Service,
+		Floor:       o.Floor,
+	}
+
+	results := make(map[string]ItemResult, len(cids))
+	var ids []string
+	for _, cid := range cids {
+		cid = strings.TrimSpace(cid)
+		if _, ok := results[cid]; ok || cid == "" {
+			continue
+		}
+		results[cid] = ItemResult{}
+		ids = append(ids, cid)
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+	canceled := func(cid string) {
+		mu.Lock()
+		results[cid] = ItemResult{Err: ctx.Err()}
+		mu.Unlock()
+	}
+	for _, cid := range ids {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			canceled(cid)
+			continue
+		}
+		if ctx.Err() != nil {
+			// both were ready and the slot won
+			<-sem
+			canceled(cid)
+			continue
+		}
+		wg.Add(1)
+		go func(cid string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			co := base
+			co.ContentID, co.Hits = cid, 1
+			i, _, err := c.Items.First(ctx, &co)
+			if err == nil && i.ContentID == "" {
+				err = ErrItemNotFound
+			}
+			mu.Lock()
+			results[cid] = ItemResult{Item: i, Err: err}
+			mu.Unlock()
+		}(cid)
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
+
 // Next updates offset
 func (o *ItemOptions) Next() (err error) {
 	o.Offset, err = nextOffset(o.Hits, o.Offset)
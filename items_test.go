@@ -1,10 +1,13 @@
 package dmm
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/usk81/generic/v2"
@@ -833,3 +836,180 @@ func TestItemOptions_GetOffset(t *testing.T) {
 		t.Errorf("GetHits returned %d", offset)
 	}
 }
+
+func TestItem_Components(t *testing.T) {
+	i := Item{
+		ItemInfo: map[string][]ItemComponent{
+			ItemInfoActress: {
+				{ID: generic.MustString(1046150), Name: `壇えみ`},
+				{ID: generic.MustString("1046150_ruby"), Name: `だんえみ`},
+				{ID: generic.MustString("1046150_classify"), Name: `av`},
+			},
+		},
+	}
+
+	expected := []ItemComponent{{ID: generic.MustString(1046150), Name: `壇えみ`}}
+	if actual := i.Components(ItemInfoActress); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Item.Components returned %+v, expected %+v", actual, expected)
+	}
+	if actual := i.Components(ItemInfoGenre); actual != nil {
+		t.Errorf("Item.Components returned %+v, expected nil", actual)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var cids, keywords []string
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		q := r.URL.Query()
+		var items string
+		switch {
+		case q.Get("cid") != "":
+			cids = append(cids, q.Get("cid"))
+			if q.Get("cid") == "juy553" {
+				items = `{"content_id":"juy553","maker_product":"JUY-553"}`
+			}
+		case q.Get("keyword") != "":
+			keywords = append(keywords, q.Get("keyword"))
+			items = `{"content_id":"hawa00123"},{"content_id":"1hawa00124"}`
+		}
+		fmt.Fprintf(w, `{"request":{"parameters":{"site":"FANZA"}},"result":{"status":200,"result_count":1,"total_count":1,"first_position":1,"items":[%s]}}`, items)
+	})
+
+	i, _, err := Lookup(ctx, client, &ItemOptions{Site: SiteAdult}, "JUY-553")
+	if err != nil {
+		t.Fatalf("Lookup returned error: %v", err)
+	}
+	if i.ContentID != "juy553" || !reflect.DeepEqual(cids, []string{"juy00553", "juy553"}) {
+		t.Errorf("Lookup returned %s after requesting %v", i.ContentID, cids)
+	}
+
+	cids = nil
+	i, _, err = Lookup(ctx, client, &ItemOptions{Site: SiteAdult}, "hawa-124")
+	if err != nil {
+		t.Fatalf("Lookup returned error: %v", err)
+	}
+	if i.ContentID != "1hawa00124" || !reflect.DeepEqual(keywords, []string{"HAWA-124"}) {
+		t.Errorf("Lookup returned %s after searching %v", i.ContentID, keywords)
+	}
+
+	if _, _, err := Lookup(ctx, client, &ItemOptions{Site: SiteAdult}, "abc-1"); err != ErrItemNotFound {
+		t.Errorf("Lookup returned %v, expected %v", err, ErrItemNotFound)
+	}
+	if _, _, err := Lookup(ctx, client, nil, "???"); err == nil {
+		t.Error("Lookup of an invalid identifier returned no error")
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	requested := map[string]int{}
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		cid := q.Get("cid")
+		mu.Lock()
+		requested[cid]++
+		mu.Unlock()
+		if q.Get("keyword") != "" || q.Get("sort") != "" || q.Get("gte_date") != "" || q.Get("hits") != "1" || q.Get("site") != SiteAdult {
+			t.Errorf("requested %s", r.URL.RawQuery)
+		}
+		switch cid {
+		case "broken":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"result":{"status":400,"message":"BAD REQUEST"}}`)
+			return
+		case "missing":
+			fmt.Fprint(w, `{"request":{"parameters":{"hits":"1"}},"result":{"status":200,"result_count":0,"total_count":0,"first_position":1,"items":[]}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"request":{"parameters":{"hits":"1"}},"result":{"status":200,"result_count":1,"total_count":1,"first_position":1,"items":[{"content_id":%q}]}}`, cid)
+	})
+	client.Use(CacheMiddleware(NewMemoryCache(), time.Minute))
+
+	cids := []string{"juy553", "ssis001", " juy553 ", "", "missing", "broken", "ssis001"}
+	rs, err := GetMany(ctx, client, cids, &GetManyOptions{
+		ItemOptions: ItemOptions{Site: SiteAdult, Keyword: "ignored", Sort: "date", GteDate: "2030-01-01T00:00:00", Hits: 100},
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("GetMany returned error: %v", err)
+	}
+	if len(rs) != 4 {
+		t.Errorf("GetMany returned %d results, expected 4: %v", len(rs), rs)
+	}
+	for _, cid := range []string{"juy553", "ssis001"} {
+		if r := rs[cid]; r.Err != nil || r.Item.ContentID != cid {
+			t.Errorf("GetMany returned %+v for %s", r, cid)
+		}
+	}
+	if r := rs["missing"]; r.Err != ErrItemNotFound {
+		t.Errorf("GetMany returned error %v for a missing item, expected %v", r.Err, ErrItemNotFound)
+	}
+	if r := rs["broken"]; r.Err == nil {
+		t.Error("GetMany returned no error for a failed request")
+	}
+	for cid, n := range requested {
+		if n != 1 {
+			t.Errorf("requested %s %d times, expected once", cid, n)
+		}
+	}
+
+	if _, err := GetMany(ctx, client, []string{"juy553", "ssis001"}, &GetManyOptions{ItemOptions: ItemOptions{Site: SiteAdult}}); err != nil {
+		t.Fatalf("GetMany returned error: %v", err)
+	}
+	if requested["juy553"] != 1 || requested["ssis001"] != 1 {
+		t.Errorf("requested %v, expected cached items not to be requested again", requested)
+	}
+}
+
+func TestGetMany_canceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("requested %s after cancellation", r.URL.RawQuery)
+	})
+
+	c, cancel := context.WithCancel(ctx)
+	cancel()
+	rs, err := GetMany(c, client, []string{"a", "b", "c"}, &GetManyOptions{Concurrency: 1})
+	if err != context.Canceled {
+		t.Errorf("GetMany returned error %v, expected %v", err, context.Canceled)
+	}
+	for _, cid := range []string{"a", "b", "c"} {
+		if rs[cid].Err != context.Canceled {
+			t.Errorf("GetMany returned %+v for %s, expected %v", rs[cid], cid, context.Canceled)
+		}
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	cases := []struct {
+		in   string
+		yen  int
+		from bool
+	}{
+		{"2381", 2381, false},
+		{"1,980", 1980, false},
+		{"300~", 300, true},
+		{" 500～ ", 500, true},
+		{"980〜", 980, true},
+	}
+	for _, c := range cases {
+		yen, from, err := ParsePrice(c.in)
+		if err != nil || yen != c.yen || from != c.from {
+			t.Errorf("ParsePrice(%q) = %d, %v, %v; expected %d, %v", c.in, yen, from, err, c.yen, c.from)
+		}
+	}
+	for _, in := range []string{"", "unknown", "-100"} {
+		if _, _, err := ParsePrice(in); err == nil {
+			t.Errorf("ParsePrice(%q) returned no error", in)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Package jsonld renders items as schema.org Product structured data in JSON-LD,
+// to be embedded in product pages.
+//
+//	tag, err := jsonld.ScriptTag(item)
+//	// <script type="application/ld+json">{"@context":"https://schema.org","@type":"Product",...}</script>
+package jsonld
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+const (
+	schemaContext = "https://schema.org"
+	currencyJPY   = "JPY"
+	dateFormat    = "2006-01-02 15:04:05"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// availabilities are the schema.org availabilities of the stock values of items
+var availabilities = map[string]string{
+	"stock":   "https://schema.org/InStock",
+	"reserve": "https://schema.org/PreOrder",
+	"empty":   "https://schema.org/OutOfStock",
+}
+
+// Product is a schema.org Product
+type Product struct {
+	Context         string           `json:"@context"`
+	Type            string           `json:"@type"`
+	Name            string           `json:"name"`
+	SKU             string           `json:"sku,omitempty"`
+	MPN             string           `json:"mpn,omitempty"`
+	GTIN13          string           `json:"gtin13,omitempty"`
+	Description     string           `json:"description,omitempty"`
+	Image           []string         `json:"image,omitempty"`
+	URL             string           `json:"url,omitempty"`
+	Category        string           `json:"category,omitempty"`
+	ReleaseDate     string           `json:"releaseDate,omitempty"`
+	Brand           *Brand           `json:"brand,omitempty"`
+	Offers          interface{}      `json:"offers,omitempty"`
+	AggregateRating *AggregateRating `json:"aggregateRating,omitempty"`
+}
+
+// Brand is a schema.org Brand
+type Brand struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// Offer is a schema.org Offer
+type Offer struct {
+	Type          string `json:"@type"`
+	Name          string `json:"name,omitempty"`
+	URL           string `json:"url,omitempty"`
+	Price         string `json:"price"`
+	PriceCurrency string `json:"priceCurrency"`
+	Availability  string `json:"availability,omitempty"`
+}
+
+// AggregateOffer is a schema.org AggregateOffer, used for items with several deliveries or a "from" price
+type AggregateOffer struct {
+	Type          string  `json:"@type"`
+	LowPrice      string  `json:"lowPrice"`
+	HighPrice     string  `json:"highPrice,omitempty"`
+	PriceCurrency string  `json:"priceCurrency"`
+	OfferCount    int     `json:"offerCount"`
+	Availability  string  `json:"availability,omitempty"`
+	Offers        []Offer `json:"offers,omitempty"`
+}
+
+// AggregateRating is a schema.org AggregateRating
+type AggregateRating struct {
+	Type        string `json:"@type"`
+	RatingValue string `json:"ratingValue"`
+	ReviewCount int    `json:"reviewCount"`
+	BestRating  string `json:"bestRating"`
+	WorstRating string `json:"worstRating"`
+}
+
+// NewProduct converts the item into a Product.
+//
+// The first maker becomes the brand. A single price becomes an Offer; several deliveries
+// or a "from" price like "300~" become an AggregateOffer, whose high price is omitted if any price is open-ended.
+// Items without a parsable price have no offers, and items without reviews no rating.
+func NewProduct(i dmm.Item) Product {
+	p := Product{
+		Context:     schemaContext,
+		Type:        "Product",
+		Name:        i.Title,
+		SKU:         i.ContentID,
+		MPN:         i.MakerProduct,
+		GTIN13:      i.JANCode,
+		Description: i.Comment,
+		URL:         i.URL,
+		Category:    i.FloorName,
+		ReleaseDate: releaseDate(i.Date),
+	}
+	if i.ImageURL.Large != "" {
+		p.Image = append(p.Image, i.ImageURL.Large)
+	}
+	if ms := i.Components(dmm.ItemInfoMaker); len(ms) > 0 {
+		p.Brand = &Brand{Type: "Brand", Name: ms[0].Name}
+	}
+	if o := offers(i); o != nil {
+		p.Offers = o
+	}
+	p.AggregateRating = rating(i.Review)
+	return p
+}
+
+// Render returns the JSON-LD of the item
+func Render(i dmm.Item) ([]byte, error) {
+	return json.Marshal(NewProduct(i))
+}
+
+// ScriptTag returns the JSON-LD of the item in a script element.
+// The JSON escapes <, > and &, so the title and comment cannot close the element.
+func ScriptTag(i dmm.Item) (string, error) {
+	data, err := Render(i)
+	if err != nil {
+		return "", err
+	}
+	return `<script type="application/ld+json">` + string(data) + `</script>`, nil
+}
+
+// offers returns the Offer or AggregateOffer of the item, or nil without prices
+func offers(i dmm.Item) interface{} {
+	availability := availabilities[i.Stock]
+
+	var os []Offer
+	from := false
+	low, high := -1, -1
+	add := func(name, price string) {
+		yen, f, err := dmm.ParsePrice(price)
+		if err != nil {
+			return
+		}
+		from = from || f
+		if low < 0 || yen < low {
+			low = yen
+		}
+		if yen > high {
+			high = yen
+		}
+		os = append(os, Offer{
+			Type:          "Offer",
+			Name:          name,
+			URL:           i.URL,
+			Price:         strconv.Itoa(yen),
+			PriceCurrency: currencyJPY,
+			Availability:  availability,
+		})
+	}
+	for _, d := range i.Prices.Deliveries.Delivery {
+		add(d.Type, d.Price)
+	}
+	if len(os) == 0 {
+		add("", i.Prices.Price)
+	}
+
+	switch {
+	case len(os) == 0:
+		return nil
+	case len(os) == 1 && !from:
+		return &os[0]
+	}
+	ao := &AggregateOffer{
+		Type:          "AggregateOffer",
+		LowPrice:      strconv.Itoa(low),
+		PriceCurrency: currencyJPY,
+		OfferCount:    len(os),
+		Availability:  availability,
+	}
+	if len(os) > 1 {
+		ao.Offers = os
+	}
+	// an open-ended price has no known maximum
+	if len(os) > 1 && !from {
+		ao.HighPrice = strconv.Itoa(high)
+	}
+	return ao
+}
+
+// rating returns the AggregateRating of the review, or nil if there are no reviews
+func rating(r dmm.Review) *AggregateRating {
+	avg, err := strconv.ParseFloat(r.Average, 64)
+	if r.Count <= 0 || err != nil || avg <= 0 {
+		return nil
+	}
+	return &AggregateRating{
+		Type:        "AggregateRating",
+		RatingValue: strconv.FormatFloat(avg, 'f', -1, 64),
+		ReviewCount: r.Count,
+		BestRating:  "5",
+		WorstRating: "1",
+	}
+}
+
+// releaseDate converts an item date in JST into ISO 8601
+func releaseDate(s string) string {
+	t, err := time.ParseInLocation(dateFormat, s, jst)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,128 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+)
+
+func testItem() dmm.Item {
+	return dmm.Item{
+		ContentID:    "juy553",
+		Title:        "タイトル <b>",
+		Comment:      "説明",
+		URL:          "https://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/",
+		MakerProduct: "JUY-553",
+		JANCode:      "4549831234567",
+		FloorName:    "DVD",
+		Date:         "2018-07-25 10:00:00",
+		Stock:        "stock",
+		ImageURL:     dmm.ImageURL{Large: "https://pics.dmm.co.jp/mono/movie/adult/juy553/juy553pl.jpg"},
+		Prices:       dmm.Prices{Price: "2381"},
+		Review:       dmm.Review{Count: 3, Average: "4.33"},
+		ItemInfo: map[string][]dmm.ItemComponent{
+			dmm.ItemInfoMaker: {{ID: generic.MustString(2661), Name: "マドンナ"}},
+		},
+	}
+}
+
+func TestNewProduct(t *testing.T) {
+	p := NewProduct(testItem())
+	expected := Product{
+		Context:     "https://schema.org",
+		Type:        "Product",
+		Name:        "タイトル <b>",
+		SKU:         "juy553",
+		MPN:         "JUY-553",
+		GTIN13:      "4549831234567",
+		Description: "説明",
+		Image:       []string{"https://pics.dmm.co.jp/mono/movie/adult/juy553/juy553pl.jpg"},
+		URL:         "https://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/",
+		Category:    "DVD",
+		ReleaseDate: "2018-07-25T10:00:00+09:00",
+		Brand:       &Brand{Type: "Brand", Name: "マドンナ"},
+		Offers: &Offer{
+			Type:          "Offer",
+			URL:           "https://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/",
+			Price:         "2381",
+			PriceCurrency: "JPY",
+			Availability:  "https://schema.org/InStock",
+		},
+		AggregateRating: &AggregateRating{Type: "AggregateRating", RatingValue: "4.33", ReviewCount: 3, BestRating: "5", WorstRating: "1"},
+	}
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("NewProduct returned %+v, expected %+v", p, expected)
+	}
+}
+
+func TestNewProduct_offers(t *testing.T) {
+	cases := []struct {
+		prices   dmm.Prices
+		expected interface{}
+	}{
+		{dmm.Prices{Price: "300~"}, &AggregateOffer{Type: "AggregateOffer", LowPrice: "300", PriceCurrency: "JPY", OfferCount: 1}},
+		{dmm.Prices{Price: "1,980"}, &Offer{Type: "Offer", Price: "1980", PriceCurrency: "JPY"}},
+		{dmm.Prices{Price: "300~", Deliveries: dmm.Deliveries{Delivery: []dmm.Delivery{
+			{Type: "stream", Price: "300"},
+			{Type: "download", Price: "980"},
+			{Type: "hd", Price: "-"},
+		}}}, &AggregateOffer{Type: "AggregateOffer", LowPrice: "300", HighPrice: "980", PriceCurrency: "JPY", OfferCount: 2, Offers: []Offer{
+			{Type: "Offer", Name: "stream", Price: "300", PriceCurrency: "JPY"},
+			{Type: "Offer", Name: "download", Price: "980", PriceCurrency: "JPY"},
+		}}},
+		{dmm.Prices{Deliveries: dmm.Deliveries{Delivery: []dmm.Delivery{
+			{Type: "stream", Price: "300~"},
+			{Type: "download", Price: "980"},
+		}}}, &AggregateOffer{Type: "AggregateOffer", LowPrice: "300", PriceCurrency: "JPY", OfferCount: 2, Offers: []Offer{
+			{Type: "Offer", Name: "stream", Price: "300", PriceCurrency: "JPY"},
+			{Type: "Offer", Name: "download", Price: "980", PriceCurrency: "JPY"},
+		}}},
+		{dmm.Prices{}, nil},
+	}
+	for _, c := range cases {
+		p := NewProduct(dmm.Item{Prices: c.prices})
+		if !reflect.DeepEqual(p.Offers, c.expected) {
+			t.Errorf("NewProduct of %+v returned offers %+v, expected %+v", c.prices, p.Offers, c.expected)
+		}
+	}
+}
+
+func TestNewProduct_missing(t *testing.T) {
+	p := NewProduct(dmm.Item{Title: "t", Review: dmm.Review{Count: 0, Average: "0.00"}, Date: "unknown"})
+	if p.AggregateRating != nil || p.Brand != nil || p.ReleaseDate != "" || p.Offers != nil {
+		t.Errorf("NewProduct returned %+v", p)
+	}
+
+	data, err := Render(dmm.Item{Title: "t"})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"offers", "aggregateRating", "brand", "image"} {
+		if _, ok := m[k]; ok {
+			t.Errorf("Render wrote %s for an item without it: %s", k, data)
+		}
+	}
+}
+
+func TestScriptTag(t *testing.T) {
+	i := testItem()
+	i.Title = "</script><script>alert(1)</script>"
+	tag, err := ScriptTag(i)
+	if err != nil {
+		t.Fatalf("ScriptTag returned error: %v", err)
+	}
+	if !strings.HasPrefix(tag, `<script type="application/ld+json">{"@context":"https://schema.org","@type":"Product"`) || !strings.HasSuffix(tag, "}</script>") {
+		t.Errorf("ScriptTag returned %s", tag)
+	}
+	if strings.Count(tag, "</script>") != 1 {
+		t.Errorf("ScriptTag did not escape the title: %s", tag)
+	}
+}
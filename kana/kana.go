@@ -0,0 +1,109 @@
+// Package kana normalizes Japanese text for name matching: it converts between hiragana
+// and katakana, folds full and half width forms, romanizes kana in Hepburn and scores
+// how similar two names are, e.g. a user's query and the Name or Ruby of an actress.
+package kana
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// hiragana ぁ..ゖ and katakana ァ..ヶ are the same distance apart
+	hiraganaStart = 'ぁ'
+	hiraganaEnd   = 'ゖ'
+	katakanaStart = 'ァ'
+	katakanaEnd   = 'ヶ'
+	kanaOffset    = katakanaStart - hiraganaStart
+)
+
+// halfwidth are the full width forms of U+FF61..U+FF9F
+var halfwidth = []rune("。「」、・ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン゛゜")
+
+const (
+	voiceable     = "カキクケコサシスセソタチツテトハヒフヘホ"
+	semiVoiceable = "ハヒフヘホ"
+)
+
+// ToKatakana converts hiragana in s to katakana
+func ToKatakana(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= hiraganaStart && r <= hiraganaEnd {
+			return r + kanaOffset
+		}
+		return r
+	}, s)
+}
+
+// ToHiragana converts katakana in s to hiragana.
+// Katakana without a hiragana counterpart, such as ヷ, is kept.
+func ToHiragana(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= katakanaStart && r <= katakanaEnd {
+			return r - kanaOffset
+		}
+		return r
+	}, s)
+}
+
+// FoldWidth converts full width ASCII and the ideographic space to ASCII,
+// and half width katakana to full width, composing voiced sound marks
+func FoldWidth(s string) string {
+	var b strings.Builder
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r >= '！' && r <= '～':
+			b.WriteRune(r - 0xfee0)
+		case r == '　':
+			b.WriteRune(' ')
+		case r >= '｡' && r <= 'ﾟ':
+			k := halfwidth[r-'｡']
+			if i+1 < len(rs) {
+				if v, ok := voice(k, rs[i+1]); ok {
+					k = v
+					i++
+				}
+			}
+			b.WriteRune(k)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// voice composes the katakana k with a half width voiced (ﾞ) or semi-voiced (ﾟ) sound mark
+func voice(k, mark rune) (rune, bool) {
+	switch {
+	case mark == 'ﾞ' && k == 'ウ':
+		return 'ヴ', true
+	case mark == 'ﾞ' && strings.ContainsRune(voiceable, k):
+		return k + 1, true
+	case mark == 'ﾟ' && strings.ContainsRune(semiVoiceable, k):
+		return k + 2, true
+	}
+	return k, false
+}
+
+// Normalize returns the form of s compared by Similarity: width folded, in hiragana and lower case,
+// without white space and middle dots
+func Normalize(s string) string {
+	s = strings.ToLower(ToHiragana(FoldWidth(s)))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '・' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Gojuon are the 46 basic hiragana in gojūon order
+const Gojuon = "あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほまみむめもやゆよらりるれろわをん"
+
+// IsGojuon reports whether s is a single basic hiragana of the gojūon table
+func IsGojuon(s string) bool {
+	rs := []rune(s)
+	return len(rs) == 1 && strings.ContainsRune(Gojuon, rs[0])
+}
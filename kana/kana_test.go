@@ -0,0 +1,86 @@
+package kana
+
+import (
+	"testing"
+)
+
+func TestToKatakana(t *testing.T) {
+	cases := map[string]string{
+		"":           "",
+		"みかみ ゆあ":     "ミカミ ユア",
+		"ゔぁいおりん":     "ヴァイオリン",
+		"カタカナと漢字abc": "カタカナト漢字abc",
+	}
+	for in, expected := range cases {
+		if got := ToKatakana(in); got != expected {
+			t.Errorf("ToKatakana(%q) returned %q, expected %q", in, got, expected)
+		}
+	}
+}
+
+func TestToHiragana(t *testing.T) {
+	cases := map[string]string{
+		"":        "",
+		"ミカミ ユア":  "みかみ ゆあ",
+		"スーパー":    "すーぱー",
+		"ヴ漢字ヷ":    "ゔ漢字ヷ",
+		"ひらがなABC": "ひらがなABC",
+	}
+	for in, expected := range cases {
+		if got := ToHiragana(in); got != expected {
+			t.Errorf("ToHiragana(%q) returned %q, expected %q", in, got, expected)
+		}
+	}
+}
+
+func TestFoldWidth(t *testing.T) {
+	cases := map[string]string{
+		"ＡＢＣ　１２３！":    "ABC 123!",
+		"ﾐｶﾐ ﾕｱ":      "ミカミ ユア",
+		"ｶﾞｯｺｳ":       "ガッコウ",
+		"ﾊﾟﾋﾟﾌﾟ":      "パピプ",
+		"ｳﾞｧｲｵﾘﾝ":     "ヴァイオリン",
+		"ｱﾞ":          "ア゛",
+		"｢ﾃｽﾄ｣･ｻﾝﾌﾟﾙ": "「テスト」・サンプル",
+	}
+	for in, expected := range cases {
+		if got := FoldWidth(in); got != expected {
+			t.Errorf("FoldWidth(%q) returned %q, expected %q", in, got, expected)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"ミカミ・ユア":     "みかみゆあ",
+		"ﾐｶﾐ ﾕｱ":     "みかみゆあ",
+		"Ｙｕａ Mikami": "yuamikami",
+	}
+	for in, expected := range cases {
+		if got := Normalize(in); got != expected {
+			t.Errorf("Normalize(%q) returned %q, expected %q", in, got, expected)
+		}
+	}
+}
+
+func TestIsGojuon(t *testing.T) {
+	if n := len([]rune(Gojuon)); n != 46 {
+		t.Errorf("Gojuon has %d characters, expected 46", n)
+	}
+	cases := map[string]bool{
+		"あ":  true,
+		"ん":  true,
+		"を":  true,
+		"":   false,
+		"が":  false,
+		"ぁ":  false,
+		"ア":  false,
+		"a":  false,
+		"あい": false,
+	}
+	for in, expected := range cases {
+		if got := IsGojuon(in); got != expected {
+			t.Errorf("IsGojuon(%q) returned %v, expected %v", in, got, expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package kana
+
+import (
+	"unicode"
+)
+
+// Similarity scores how alike a and b are after Normalize, from 0 (nothing in common) to 1 (equal).
+// The score is one minus the edit distance divided by the length of the longer string.
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(Normalize(a)), []rune(Normalize(b))
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	max := len(ra)
+	if len(rb) > max {
+		max = len(rb)
+	}
+	return 1 - float64(distance(ra, rb))/float64(max)
+}
+
+// Match returns the best Similarity of the query to any of the candidates,
+// typically the name and the reading of an entity.
+// A query in Latin letters, e.g. "yua mikami", is also compared with the romanized candidates.
+func Match(query string, candidates ...string) float64 {
+	latin := isLatin(query)
+	best := 0.0
+	for _, c := range candidates {
+		if s := Similarity(query, c); s > best {
+			best = s
+		}
+		if latin {
+			if s := Similarity(query, Romanize(c)); s > best {
+				best = s
+			}
+		}
+	}
+	return best
+}
+
+// isLatin reports whether s has letters, all of them Latin
+func isLatin(s string) bool {
+	letter := false
+	for _, r := range FoldWidth(s) {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+		letter = true
+	}
+	return letter
+}
+
+// distance is the Levenshtein distance of a and b
+func distance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
@@ -0,0 +1,59 @@
+package kana
+
+import (
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"みかみゆあ", "ミカミ・ユア", 1},
+		{"ﾐｶﾐ", "みかみ", 1},
+		{"みかみゆあ", "みかみゆい", 0.8},
+		{"あいう", "かきく", 0},
+		{"", "あ", 0},
+	}
+	for _, c := range cases {
+		if got := Similarity(c.a, c.b); got != c.expected {
+			t.Errorf("Similarity(%q, %q) returned %v, expected %v", c.a, c.b, got, c.expected)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		query      string
+		candidates []string
+		expected   float64
+	}{
+		{"三上悠亜", []string{"三上悠亜", "みかみゆあ"}, 1},
+		{"ミカミユア", []string{"三上悠亜", "みかみゆあ"}, 1},
+		{"Yua Mikami", []string{"三上悠亜", "ゆあみかみ"}, 1},
+		{"mikami yua", []string{"三上悠亜", "みかみゆあ"}, 1},
+		{"mikami yuu", []string{"三上悠亜", "みかみゆあ"}, 1 - 1.0/9},
+		{"別人", []string{"三上悠亜", "みかみゆあ"}, 0},
+	}
+	for _, c := range cases {
+		if got := Match(c.query, c.candidates...); got != c.expected {
+			t.Errorf("Match(%q, %q) returned %v, expected %v", c.query, c.candidates, got, c.expected)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"kitten", "sitting", 3},
+		{"あいう", "あう", 1},
+	}
+	for _, c := range cases {
+		if got := distance([]rune(c.a), []rune(c.b)); got != c.expected {
+			t.Errorf("distance(%q, %q) returned %d, expected %d", c.a, c.b, got, c.expected)
+		}
+	}
+}
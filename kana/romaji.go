@@ -0,0 +1,122 @@
+package kana
+
+import (
+	"strings"
+)
+
+// romaji are the Hepburn spellings of single hiragana
+var romaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+// Romanize spells the kana in s in Hepburn romanization, leaving other characters as they are.
+//
+// Palatalized syllables (きゃ kya, しょ sho), small vowels in loanwords (ファ fa, ティ ti),
+// the geminate っ (がっこう gakkou, まっちゃ matcha) and ん before vowels (きんえん kin'en) are handled.
+// Long vowels are spelled by repeating the vowel: both おう and オー become "ou" and "oo", not ō.
+func Romanize(s string) string {
+	rs := []rune(ToHiragana(FoldWidth(s)))
+	var b strings.Builder
+	geminate := false
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch r {
+		case 'っ':
+			geminate = true
+			continue
+		case 'ー':
+			if v := lastVowel(b.String()); v != 0 {
+				b.WriteByte(v)
+			}
+			continue
+		case 'ん':
+			b.WriteString("n")
+			if i+1 < len(rs) {
+				if n := romaji[rs[i+1]]; n != "" && strings.IndexByte("aiueoy", n[0]) >= 0 {
+					b.WriteString("'")
+				}
+			}
+			continue
+		}
+
+		syl, ok := romaji[r]
+		if !ok {
+			geminate = false
+			b.WriteRune(r)
+			continue
+		}
+		if i+1 < len(rs) {
+			if c, ok := combine(syl, rs[i+1]); ok {
+				syl = c
+				i++
+			}
+		}
+		if geminate {
+			if strings.HasPrefix(syl, "ch") {
+				b.WriteByte('t')
+			} else if strings.IndexByte("aiueo", syl[0]) < 0 {
+				b.WriteByte(syl[0])
+			}
+			geminate = false
+		}
+		b.WriteString(syl)
+	}
+	return b.String()
+}
+
+// combine joins a syllable with a following small kana
+func combine(syl string, small rune) (string, bool) {
+	switch small {
+	case 'ゃ', 'ゅ', 'ょ':
+		if len(syl) < 2 || !strings.HasSuffix(syl, "i") {
+			return "", false
+		}
+		stem := strings.TrimSuffix(syl, "i")
+		v := romaji[small][1:]
+		if stem == "sh" || stem == "ch" || stem == "j" {
+			return stem + v, true
+		}
+		return stem + "y" + v, true
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		v := romaji[small]
+		switch syl {
+		case "fu", "vu":
+			return syl[:1] + v, true
+		case "te", "de":
+			return syl[:1] + v, true
+		case "u":
+			return "w" + v, true
+		case "shi", "chi", "ji":
+			if v == "e" {
+				return strings.TrimSuffix(syl, "i") + v, true
+			}
+		}
+	}
+	return "", false
+}
+
+func lastVowel(s string) byte {
+	for i := len(s) - 1; i >= 0; i-- {
+		if strings.IndexByte("aiueo", s[i]) >= 0 {
+			return s[i]
+		}
+	}
+	return 0
+}
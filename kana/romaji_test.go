@@ -0,0 +1,37 @@
+package kana
+
+import (
+	"testing"
+)
+
+func TestRomanize(t *testing.T) {
+	cases := map[string]string{
+		"":       "",
+		"みかみ ゆあ": "mikami yua",
+		"しんじゅく":  "shinjuku",
+		"きょうと":   "kyouto",
+		"ちゃちゅちょ": "chachucho",
+		"じゃじゅじょ": "jajujo",
+		"りゅうせい":  "ryuusei",
+		"がっこう":   "gakkou",
+		"まっちゃ":   "matcha",
+		"きんえん":   "kin'en",
+		"こんや":    "kon'ya",
+		"さんぽ":    "sanpo",
+		"スーパー":   "suupaa",
+		"ファイル":   "fairu",
+		"ティーシャツ": "tiishatsu",
+		"ウィンドウ":  "windou",
+		"チェック":   "chekku",
+		"ヴァイオリン": "vaiorin",
+		"ﾐｶﾐ":    "mikami",
+		"東京タワー":  "東京tawaa",
+		"ABCあいう": "ABCaiu",
+		"ふじ さん":  "fuji san",
+	}
+	for in, expected := range cases {
+		if got := Romanize(in); got != expected {
+			t.Errorf("Romanize(%q) returned %q, expected %q", in, got, expected)
+		}
+	}
+}
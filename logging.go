@@ -0,0 +1,176 @@
+package dmm
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LogLevel is the severity an API call is logged at.
+type LogLevel int
+
+const (
+	// LogLevelDebug logs at debug level
+	LogLevelDebug LogLevel = iota
+	// LogLevelInfo logs at info level
+	LogLevelInfo
+	// LogLevelWarn logs at warn level
+	LogLevelWarn
+	// LogLevelError logs at error level
+	LogLevelError
+)
+
+const redacted = "REDACTED"
+
+// credentialParams are the query parameters never written to logs or error messages
+var credentialParams = []string{"api_id", "affiliate_id"}
+
+// Logger is a structured logger taking alternating key/value pairs.
+// *slog.Logger satisfies this interface.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+	InfoContext(ctx context.Context, msg string, args ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...interface{})
+	ErrorContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// SetLogger is a client option for logging every API call.
+// Successful calls are logged at the success level and failed calls at the failure level.
+func SetLogger(l Logger, success, failure LogLevel) ClientOpt {
+	return UseMiddleware(LoggingMiddleware(l, success, failure))
+}
+
+// LoggingMiddleware returns a Middleware logging the endpoint, parameters, HTTP status,
+// result counts and latency of every API call. Credentials are redacted.
+func LoggingMiddleware(l Logger, success, failure LogLevel) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			start := time.Now()
+			resp, err := next(ctx, call)
+
+			u := call.Request.URL
+			args := []interface{}{
+				"endpoint", endpoint(u),
+				"params", redactURL(u).RawQuery,
+			}
+			if resp != nil {
+				if resp.Response != nil {
+					args = append(args, "status", resp.StatusCode)
+				}
+				args = append(args,
+					"result_count", resp.ResultCount,
+					"total_count", resp.TotalCount,
+				)
+			}
+			args = append(args, "latency", time.Since(start))
+
+			if err != nil {
+				args = append(args, "error", redactError(err, u))
+				logAt(ctx, l, failure, "dmm: request failed", args...)
+				return resp, err
+			}
+			logAt(ctx, l, success, "dmm: request", args...)
+			return resp, err
+		}
+	}
+}
+
+func logAt(ctx context.Context, l Logger, level LogLevel, msg string, args ...interface{}) {
+	switch {
+	case level <= LogLevelDebug:
+		l.DebugContext(ctx, msg, args...)
+	case level == LogLevelInfo:
+		l.InfoContext(ctx, msg, args...)
+	case level == LogLevelWarn:
+		l.WarnContext(ctx, msg, args...)
+	default:
+		l.ErrorContext(ctx, msg, args...)
+	}
+}
+
+// endpoint returns the API base path of u, e.g. affiliate/v3/ItemList
+func endpoint(u *url.URL) string {
+	p := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(p, "affiliate/"); i > 0 {
+		p = p[i:]
+	}
+	return p
+}
+
+// redactURL returns a copy of u whose credential query parameters are masked.
+func redactURL(u *url.URL) *url.URL {
+	ru := *u
+	q := ru.Query()
+	for _, k := range credentialParams {
+		if _, ok := q[k]; ok {
+			q.Set(k, redacted)
+		}
+	}
+	ru.RawQuery = q.Encode()
+	return &ru
+}
+
+// redactError formats err with the credential query parameters of the request URL u masked.
+// Transport errors embed the full request URL, so their URL is redacted; other text is left as is.
+func redactError(err error, u *url.URL) string {
+	if ue, ok := err.(*url.Error); ok {
+		re := *ue
+		if pu, perr := url.Parse(ue.URL); perr == nil {
+			re.URL = redactURL(pu).String()
+		}
+		return re.Error()
+	}
+	return strings.Replace(err.Error(), u.String(), redactURL(u).String(), -1)
+}
+
+// redactCredentials masks the credentials found in the query of u within s.
+// This also covers affiliate URLs, which embed the affiliate ID in their path.
+// Only whole tokens are masked, so a short credential never mangles the words containing it.
+func redactCredentials(s string, u *url.URL) string {
+	q := u.Query()
+	secrets := map[string]bool{}
+	for _, k := range credentialParams {
+		for _, v := range q[k] {
+			if v != "" {
+				secrets[v] = true
+				secrets[url.QueryEscape(v)] = true
+			}
+		}
+	}
+	if len(secrets) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if t := s[start:end]; secrets[t] {
+			b.WriteString(redacted)
+		} else {
+			b.WriteString(t)
+		}
+		start = -1
+	}
+	for i, r := range s {
+		if isTokenRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		b.WriteRune(r)
+	}
+	flush(len(s))
+	return b.String()
+}
+
+// isTokenRune reports whether r can be part of a credential
+func isTokenRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '%'
+}
@@ -0,0 +1,166 @@
+package dmm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type testLogEntry struct {
+	level LogLevel
+	msg   string
+	args  map[string]interface{}
+}
+
+type testLogger struct {
+	entries []testLogEntry
+}
+
+func (l *testLogger) add(level LogLevel, msg string, args []interface{}) {
+	e := testLogEntry{level: level, msg: msg, args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		e.args[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, e)
+}
+
+func (l *testLogger) DebugContext(_ context.Context, msg string, args ...interface{}) {
+	l.add(LogLevelDebug, msg, args)
+}
+
+func (l *testLogger) InfoContext(_ context.Context, msg string, args ...interface{}) {
+	l.add(LogLevelInfo, msg, args)
+}
+
+func (l *testLogger) WarnContext(_ context.Context, msg string, args ...interface{}) {
+	l.add(LogLevelWarn, msg, args)
+}
+
+func (l *testLogger) ErrorContext(_ context.Context, msg string, args ...interface{}) {
+	l.add(LogLevelError, msg, args)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+actressBasePath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testActressesRequest)
+	})
+
+	l := &testLogger{}
+	client.Use(LoggingMiddleware(l, LogLevelDebug, LogLevelError))
+
+	_, _, err := client.Actresses.List(ctx, &ActressOptions{APIID: "secret-api", AffiliateID: "affiliate-990", Hits: 10})
+	if err != nil {
+		t.Fatalf("Actresses.List returned error: %v", err)
+	}
+
+	if len(l.entries) != 1 {
+		t.Fatalf("logged %d entries, expected 1", len(l.entries))
+	}
+	e := l.entries[0]
+	if e.level != LogLevelDebug {
+		t.Errorf("level = %v, expected %v", e.level, LogLevelDebug)
+	}
+	expected := map[string]interface{}{
+		"endpoint":     actressBasePath,
+		"params":       "affiliate_id=REDACTED&api_id=REDACTED&hits=10",
+		"status":       http.StatusOK,
+		"result_count": 10,
+		"total_count":  48122,
+	}
+	for k, v := range expected {
+		if e.args[k] != v {
+			t.Errorf("%s = %v, expected %v", k, e.args[k], v)
+		}
+	}
+	if _, ok := e.args["latency"]; !ok {
+		t.Error("latency was not logged")
+	}
+}
+
+func TestLoggingMiddleware_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+actressBasePath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"result":{"status":400,"message":"bad request http://www.dmm.co.jp/digital/-/list/=/affiliate-990","errors":{"api_id":"secret-api is invalid"}}}`)
+	})
+
+	l := &testLogger{}
+	client.Use(LoggingMiddleware(l, LogLevelDebug, LogLevelWarn))
+
+	_, _, err := client.Actresses.List(ctx, &ActressOptions{APIID: "secret-api", AffiliateID: "affiliate-990"})
+	if err == nil {
+		t.Fatal("Expected error to be returned")
+	}
+
+	if len(l.entries) != 1 {
+		t.Fatalf("logged %d entries, expected 1", len(l.entries))
+	}
+	e := l.entries[0]
+	if e.level != LogLevelWarn {
+		t.Errorf("level = %v, expected %v", e.level, LogLevelWarn)
+	}
+	if e.args["status"] != http.StatusBadRequest {
+		t.Errorf("status = %v, expected %v", e.args["status"], http.StatusBadRequest)
+	}
+	for k, v := range e.args {
+		s := fmt.Sprint(v)
+		if strings.Contains(s, "secret-api") || strings.Contains(s, "affiliate-990") {
+			t.Errorf("%s leaks credentials: %s", k, s)
+		}
+	}
+}
+
+func TestErrorResponse_Error_redaction(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://api.dmm.com/affiliate/v3/ItemList?api_id=secret-api&affiliate_id=affiliate-990&hits=10", nil)
+	r := &ErrorResponse{
+		Response: &http.Response{Request: req, StatusCode: http.StatusBadRequest},
+		Result: ErrResult{
+			Status:  400,
+			Message: "invalid affiliate http://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/affiliate-990",
+		},
+	}
+
+	expected := "GET https://api.dmm.com/affiliate/v3/ItemList?affiliate_id=REDACTED&api_id=REDACTED&hits=10: 400 invalid affiliate http://www.dmm.co.jp/mono/dvd/-/detail/=/cid=juy553/REDACTED"
+	if got := r.Error(); got != expected {
+		t.Errorf("ErrorResponse.Error() = %s, expected %s", got, expected)
+	}
+}
+
+func TestRedactCredentials(t *testing.T) {
+	u, _ := url.Parse("https://api.dmm.com/affiliate/v3/ItemList?api_id=secret-api&affiliate_id=a-99&hits=10")
+	cases := []struct {
+		s, expected string
+	}{
+		{"invalid parameter a-99 (see manual)", "invalid parameter REDACTED (see manual)"},
+		{"http://www.dmm.co.jp/digital/-/list/=/a-99", "http://www.dmm.co.jp/digital/-/list/=/REDACTED"},
+		{"affiliate a-990 and data-99 are unrelated", "affiliate a-990 and data-99 are unrelated"},
+		{"secret-api is invalid", "REDACTED is invalid"},
+	}
+	for _, c := range cases {
+		if got := redactCredentials(c.s, u); got != c.expected {
+			t.Errorf("redactCredentials(%q) = %q, expected %q", c.s, got, c.expected)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	u, _ := url.Parse("https://api.dmm.com/affiliate/v3/ItemList?api_id=secret-api&affiliate_id=a&hits=10")
+
+	ue := &url.Error{Op: "Get", URL: u.String(), Err: fmt.Errorf("dial tcp: connection refused")}
+	got := redactError(ue, u)
+	if !strings.Contains(got, "ItemList?affiliate_id=REDACTED&api_id=REDACTED&hits=10") || strings.Contains(got, "secret-api") {
+		t.Errorf("redactError(%v) = %s, expected the URL to be redacted", ue, got)
+	}
+
+	if got := redactError(fmt.Errorf("a parameter is invalid"), u); got != "a parameter is invalid" {
+		t.Errorf("redactError mangled the message: %s", got)
+	}
+}
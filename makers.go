@@ -64,6 +64,13 @@ type Maker struct {
 	FloorCode   string
 }
 
+// MakerIndex is an index of makers by initial
+type MakerIndex struct {
+	// Initials are the indexed initials in gojūon order
+	Initials []string
+	Makers   map[string][]Maker
+}
+
 // MakerOptions specifies the optional parameters to various List methods
 type MakerOptions struct {
 	APIID       string `json:"api_id" url:"api_id"`
@@ -88,20 +95,21 @@ type internalMakerOptions struct {
 }
 
 func (r *makerRoot) populatePageValues(res *Response) {
+	res.ResultStatus = r.Result.Status.Int()
 	res.FirstPosition = r.Result.FirstPosition.Int()
 	res.ResultCount = r.Result.ResultCount.Int()
 	res.TotalCount = r.Result.TotalCount.Int()
 	res.Parameters = r.Request.Parameters.Convert()
 }
 
-func (s *MakersServiceOp) list(ctx context.Context, path string) (makerResult, *Response, error) {
+func (s *MakersServiceOp) list(ctx context.Context, path string, opt ListOptions) (makerResult, *Response, error) {
 	req, err := s.client.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return makerResult{}, nil, err
 	}
 
 	var root makerRoot
-	resp, err := s.client.Do(ctx, req, &root)
+	resp, err := s.client.do(ctx, &Call{Request: req, Options: listOptions(opt), Value: &root})
 	if err != nil {
 		return makerResult{}, resp, err
 	}
@@ -126,7 +134,7 @@ func (s *MakersServiceOp) List(ctx context.Context, opt *MakerOptions) ([]Maker,
 	}
 	var res makerResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return nil, r, err
 	}
@@ -155,7 +163,7 @@ func (s *MakersServiceOp) Unmarshal(ctx context.Context, opt *MakerOptions, out
 	}
 	var res makerResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return r, err
 	}
@@ -168,6 +176,66 @@ func (s *MakersServiceOp) Unmarshal(ctx context.Context, opt *MakerOptions, out
 	return r, err
 }
 
+// ResolveMaker returns the maker of the floor whose name or reading best matches name, as scored by kana.Match,
+// searching every page of opt. ErrNotResolved is returned if no maker scores ResolveThreshold.
+func ResolveMaker(ctx context.Context, c *Client, opt *MakerOptions, name string) (Maker, *Response, error) {
+	var o MakerOptions
+	if opt != nil {
+		o = *opt
+	}
+	resolvePage(&o.Hits, &o.Offset)
+
+	var best Maker
+	var resp *Response
+	m := &matcher{name: name}
+	err := c.resolve(ctx, &o, m, func(ctx context.Context) (*Response, error) {
+		ms, r, err := c.Makers.List(ctx, &o)
+		resp = r
+		for _, mk := range ms {
+			if m.consider(mk.Name, mk.Ruby) {
+				best = mk
+			}
+		}
+		return r, err
+	})
+	if err != nil {
+		return Maker{}, resp, err
+	}
+	return best, resp, nil
+}
+
+// IndexMakers fetches every page of makers of opt for each initial of the gojūon table, or only for opt.Initial if it is set,
+// running at most concurrency initials at once (DefaultIndexConcurrency if 0).
+// An *InitialError is returned if opt.Initial is not a kana.
+func IndexMakers(ctx context.Context, c *Client, opt *MakerOptions, concurrency int) (MakerIndex, error) {
+	var o MakerOptions
+	if opt != nil {
+		o = *opt
+	}
+	initials, lists, err := fetchIndex(ctx, o.Initial, concurrency, func(ctx context.Context, initial string) (interface{}, error) {
+		po := o
+		po.Initial = initial
+		indexPage(&po.Hits, &po.Offset)
+
+		var ms []Maker
+		err := c.Paginate(ctx, &po, func(ctx context.Context) (*Response, error) {
+			page, r, err := c.Makers.List(ctx, &po)
+			ms = append(ms, page...)
+			return r, err
+		})
+		return ms, err
+	})
+	if err != nil {
+		return MakerIndex{}, err
+	}
+
+	idx := MakerIndex{Initials: initials, Makers: make(map[string][]Maker, len(lists))}
+	for initial, l := range lists {
+		idx.Makers[initial] = l.([]Maker)
+	}
+	return idx, nil
+}
+
 // Next updates offset
 func (o *MakerOptions) Next() (err error) {
 	o.Offset, err = nextOffset(o.Hits, o.Offset)
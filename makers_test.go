@@ -381,3 +381,28 @@ func TestMakerOptions_GetOffset(t *testing.T) {
 		t.Errorf("GetHits returned %d", offset)
 	}
 }
+
+func TestResolveMaker(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+makerBasePath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, testMakersRequest)
+	})
+
+	for _, name := range []string{`ｱｯﾌﾟｿﾝ`} {
+		actual, _, err := ResolveMaker(ctx, client, &MakerOptions{FloorID: "43"}, name)
+		if err != nil {
+			t.Errorf("ResolveMaker(%q) returned error: %v", name, err)
+			continue
+		}
+		if actual.MakerID != `46068` {
+			t.Errorf("ResolveMaker(%q) returned %+v, expected MakerID 46068", name, actual)
+		}
+	}
+
+	if _, _, err := ResolveMaker(ctx, client, &MakerOptions{FloorID: "43"}, `該当なし`); err != ErrNotResolved {
+		t.Errorf("ResolveMaker returned %v, expected %v", err, ErrNotResolved)
+	}
+}
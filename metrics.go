@@ -0,0 +1,227 @@
+package dmm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the latency histogram of Metrics
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// CallMetrics describes a finished API call
+type CallMetrics struct {
+	// Endpoint is the API base path, e.g. affiliate/v3/ItemList
+	Endpoint string
+	// HTTPStatus is the HTTP status code, or 0 if no response was received
+	HTTPStatus int
+	// ResultStatus is the DMM result.status, or 0 if it is unknown
+	ResultStatus int
+	// Items is the number of returned entities (result_count)
+	Items int
+	// Latency is the time spent on the call
+	Latency time.Duration
+	// Err is the error the call failed with
+	Err error
+}
+
+// MetricsCollector records metrics of API calls.
+type MetricsCollector interface {
+	Observe(CallMetrics)
+}
+
+// SetMetrics is a client option for recording metrics of every API call.
+func SetMetrics(m MetricsCollector) ClientOpt {
+	return UseMiddleware(MetricsMiddleware(m))
+}
+
+// MetricsMiddleware returns a Middleware reporting every API call to m.
+func MetricsMiddleware(m MetricsCollector) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			start := time.Now()
+			resp, err := next(ctx, call)
+
+			cm := CallMetrics{
+				Endpoint: endpoint(call.Request.URL),
+				Latency:  time.Since(start),
+				Err:      err,
+			}
+			if resp != nil {
+				if resp.Response != nil {
+					cm.HTTPStatus = resp.StatusCode
+				}
+				cm.ResultStatus = resp.ResultStatus
+				cm.Items = resp.ResultCount
+			}
+			if er, ok := err.(*ErrorResponse); ok && er.Result.Status != 0 {
+				cm.ResultStatus = er.Result.Status
+			}
+			m.Observe(cm)
+			return resp, err
+		}
+	}
+}
+
+// Metrics is the default MetricsCollector. It keeps Prometheus style counters and a
+// latency histogram in memory, and serves them in the Prometheus text format as an http.Handler.
+type Metrics struct {
+	mu       sync.Mutex
+	buckets  []float64
+	requests map[requestKey]uint64
+	errors   map[string]uint64
+	items    map[string]uint64
+	latency  map[string]*histogram
+}
+
+type requestKey struct {
+	endpoint     string
+	httpStatus   int
+	resultStatus int
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+var _ MetricsCollector = &Metrics{}
+var _ http.Handler = &Metrics{}
+
+// NewMetrics returns a new Metrics. If no buckets are given, DefaultLatencyBuckets are used.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	bs := append([]float64(nil), buckets...)
+	sort.Float64s(bs)
+	return &Metrics{
+		buckets:  bs,
+		requests: map[requestKey]uint64{},
+		errors:   map[string]uint64{},
+		items:    map[string]uint64{},
+		latency:  map[string]*histogram{},
+	}
+}
+
+// Observe records a finished API call
+func (m *Metrics) Observe(cm CallMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{cm.Endpoint, cm.HTTPStatus, cm.ResultStatus}]++
+	if cm.Err != nil {
+		m.errors[cm.Endpoint]++
+	}
+	m.items[cm.Endpoint] += uint64(cm.Items)
+
+	h, ok := m.latency[cm.Endpoint]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[cm.Endpoint] = h
+	}
+	sec := cm.Latency.Seconds()
+	for i, b := range m.buckets {
+		if sec <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += sec
+	h.count++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP dmm_requests_total Total number of DMM API requests.\n")
+	b.WriteString("# TYPE dmm_requests_total counter\n")
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+		if keys[i].httpStatus != keys[j].httpStatus {
+			return keys[i].httpStatus < keys[j].httpStatus
+		}
+		return keys[i].resultStatus < keys[j].resultStatus
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&b, "dmm_requests_total{endpoint=%s,code=%s,result_status=%s} %d\n",
+			quoteLabel(k.endpoint), quoteLabel(statusLabel(k.httpStatus)), quoteLabel(statusLabel(k.resultStatus)), m.requests[k])
+	}
+
+	b.WriteString("# HELP dmm_request_errors_total Total number of failed DMM API requests.\n")
+	b.WriteString("# TYPE dmm_request_errors_total counter\n")
+	for _, e := range sortedKeys(m.errors) {
+		fmt.Fprintf(&b, "dmm_request_errors_total{endpoint=%s} %d\n", quoteLabel(e), m.errors[e])
+	}
+
+	b.WriteString("# HELP dmm_items_returned_total Total number of entities returned by the DMM API.\n")
+	b.WriteString("# TYPE dmm_items_returned_total counter\n")
+	for _, e := range sortedKeys(m.items) {
+		fmt.Fprintf(&b, "dmm_items_returned_total{endpoint=%s} %d\n", quoteLabel(e), m.items[e])
+	}
+
+	b.WriteString("# HELP dmm_request_duration_seconds Latency of DMM API requests.\n")
+	b.WriteString("# TYPE dmm_request_duration_seconds histogram\n")
+	endpoints := make([]string, 0, len(m.latency))
+	for e := range m.latency {
+		endpoints = append(endpoints, e)
+	}
+	sort.Strings(endpoints)
+	for _, e := range endpoints {
+		h := m.latency[e]
+		for i, le := range m.buckets {
+			fmt.Fprintf(&b, "dmm_request_duration_seconds_bucket{endpoint=%s,le=%s} %d\n",
+				quoteLabel(e), quoteLabel(strconv.FormatFloat(le, 'g', -1, 64)), h.counts[i])
+		}
+		fmt.Fprintf(&b, "dmm_request_duration_seconds_bucket{endpoint=%s,le=\"+Inf\"} %d\n", quoteLabel(e), h.count)
+		fmt.Fprintf(&b, "dmm_request_duration_seconds_sum{endpoint=%s} %s\n", quoteLabel(e), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "dmm_request_duration_seconds_count{endpoint=%s} %d\n", quoteLabel(e), h.count)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+func statusLabel(s int) string {
+	if s == 0 {
+		return ""
+	}
+	return strconv.Itoa(s)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,76 @@
+package dmm
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+actressBasePath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testActressesRequest)
+	})
+	mux.HandleFunc(`/`+genreBasePath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"result":{"status":400,"message":"LOGIN ERROR"}}`)
+	})
+
+	m := NewMetrics()
+	client.Use(MetricsMiddleware(m))
+
+	if _, _, err := client.Actresses.List(ctx, nil); err != nil {
+		t.Fatalf("Actresses.List returned error: %v", err)
+	}
+	if _, _, err := client.Actresses.List(ctx, nil); err != nil {
+		t.Fatalf("Actresses.List returned error: %v", err)
+	}
+	if _, _, err := client.Genres.List(ctx, nil); err == nil {
+		t.Fatal("Expected error to be returned")
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		`dmm_requests_total{endpoint="affiliate/v3/ActressSearch",code="200",result_status="200"} 2`,
+		`dmm_requests_total{endpoint="affiliate/v3/GenreSearch",code="400",result_status="400"} 1`,
+		`dmm_request_errors_total{endpoint="affiliate/v3/GenreSearch"} 1`,
+		`dmm_items_returned_total{endpoint="affiliate/v3/ActressSearch"} 20`,
+		`dmm_request_duration_seconds_bucket{endpoint="affiliate/v3/ActressSearch",le="+Inf"} 2`,
+		`dmm_request_duration_seconds_count{endpoint="affiliate/v3/GenreSearch"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("metrics output does not contain %q:\n%s", line, body)
+		}
+	}
+}
+
+func TestMetrics_Observe_buckets(t *testing.T) {
+	m := NewMetrics(1, 0.1)
+	m.Observe(CallMetrics{Endpoint: "e", Latency: 50 * time.Millisecond})
+	m.Observe(CallMetrics{Endpoint: "e", Latency: 500 * time.Millisecond})
+	m.Observe(CallMetrics{Endpoint: "e", Latency: 5 * time.Second})
+
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatalf("Metrics.WriteTo returned error: %v", err)
+	}
+	for _, line := range []string{
+		`dmm_request_duration_seconds_bucket{endpoint="e",le="0.1"} 1`,
+		`dmm_request_duration_seconds_bucket{endpoint="e",le="1"} 2`,
+		`dmm_request_duration_seconds_bucket{endpoint="e",le="+Inf"} 3`,
+		`dmm_request_duration_seconds_sum{endpoint="e"} 5.55`,
+		`dmm_requests_total{endpoint="e",code="",result_status=""} 3`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("metrics output does not contain %q:\n%s", line, b.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package dmm
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// Call is a single API call passing through the middleware chain of a Client.
+type Call struct {
+	// Request is the HTTP request to be sent. Middleware may mutate or replace it.
+	Request *http.Request
+
+	// Options are the typed parameters the request was built from.
+	// It is nil when the request was not created by one of the services.
+	Options ListOptions
+
+	// Value is the destination the response body is decoded into.
+	Value interface{}
+}
+
+// Handler sends a Call and returns the API response.
+type Handler func(context.Context, *Call) (*Response, error)
+
+// Middleware wraps a Handler to add behaviour around every API call,
+// e.g. logging, header injection, metrics or caching.
+type Middleware func(Handler) Handler
+
+// Use appends middleware to the client's chain.
+// Middleware added first is the outermost one and sees the call first.
+func (c *Client) Use(mw ...Middleware) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+func (c *Client) handler() Handler {
+	h := Handler(c.send)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	return h
+}
+
+// listOptions returns nil instead of an interface holding a nil pointer,
+// so middleware can simply check Call.Options against nil.
+func listOptions(opt ListOptions) ListOptions {
+	if v := reflect.ValueOf(opt); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	return opt
+}
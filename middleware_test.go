@@ -0,0 +1,111 @@
+package dmm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMiddleware_order(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+actressBasePath, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Test"); got != "outer,inner" {
+			t.Errorf("X-Test header = %q, expected %q", got, "outer,inner")
+		}
+		fmt.Fprint(w, testActressesRequest)
+	})
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) (*Response, error) {
+				calls = append(calls, name+":before")
+				v := name
+				if h := call.Request.Header.Get("X-Test"); h != "" {
+					v = h + "," + name
+				}
+				call.Request.Header.Set("X-Test", v)
+				resp, err := next(ctx, call)
+				calls = append(calls, name+":after")
+				return resp, err
+			}
+		}
+	}
+	client.Use(trace("outer"), trace("inner"))
+
+	if _, _, err := client.Actresses.List(ctx, nil); err != nil {
+		t.Fatalf("Actresses.List returned error: %v", err)
+	}
+
+	expected := []string{"outer:before", "inner:before", "inner:after", "outer:after"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("middleware calls = %v, expected %v", calls, expected)
+	}
+}
+
+func TestMiddleware_options(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+actressBasePath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testActressesRequest)
+	})
+
+	var (
+		gotOptions ListOptions
+		gotResp    *Response
+	)
+	client.Use(func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			gotOptions = call.Options
+			resp, err := next(ctx, call)
+			gotResp = resp
+			return resp, err
+		}
+	})
+
+	opt := &ActressOptions{Hits: 10, Offset: 10}
+	if _, _, err := client.Actresses.List(ctx, opt); err != nil {
+		t.Fatalf("Actresses.List returned error: %v", err)
+	}
+	if gotOptions != opt {
+		t.Errorf("Call.Options = %#v, expected %#v", gotOptions, opt)
+	}
+	if gotResp == nil || gotResp.TotalCount != 48122 || gotResp.ResultCount != 10 {
+		t.Errorf("Response paging values were not populated: %+v", gotResp)
+	}
+
+	if _, _, err := client.Actresses.List(ctx, nil); err != nil {
+		t.Fatalf("Actresses.List returned error: %v", err)
+	}
+	if gotOptions != nil {
+		t.Errorf("Call.Options = %#v, expected nil", gotOptions)
+	}
+}
+
+func TestMiddleware_shortCircuit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+actressBasePath, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the server")
+	})
+
+	expected := fmt.Errorf("blocked")
+	c, err := New(nil, SetBaseURL(server.URL), UseMiddleware(func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			return nil, expected
+		}
+	}))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if _, _, err := c.Actresses.List(ctx, nil); err != expected {
+		t.Errorf("Actresses.List returned error %v, expected %v", err, expected)
+	}
+}
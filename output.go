@@ -0,0 +1,93 @@
+package dmm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// Output formats of the DMM Affiliate API
+const (
+	OutputJSON = "json"
+	OutputXML  = "xml"
+)
+
+// OutputError reports a combination of the output and callback parameters
+// that cannot be decoded. It is returned before the request is sent.
+type OutputError struct {
+	Output   string
+	Callback string
+	Reason   string
+}
+
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("dmm: unsupported output=%q callback=%q: %s", e.Output, e.Callback, e.Reason)
+}
+
+// checkOutput rejects output formats which can not be decoded into v.
+// Any format can be written raw into an io.Writer.
+func checkOutput(u *url.URL, v interface{}) error {
+	q := u.Query()
+	output, callback := q.Get("output"), q.Get("callback")
+	format := strings.ToLower(output)
+	if _, ok := v.(io.Writer); ok || v == nil {
+		if format == OutputXML && callback != "" {
+			return &OutputError{output, callback, "callback requires json output"}
+		}
+		return nil
+	}
+
+	switch format {
+	case "", OutputJSON:
+		return nil
+	case OutputXML:
+		if callback != "" {
+			return &OutputError{output, callback, "callback requires json output"}
+		}
+		return &OutputError{output, callback, "xml responses can only be written raw to an io.Writer"}
+	}
+	return &OutputError{output, callback, "unknown output format"}
+}
+
+// decodeBody decodes a JSON or JSONP response body into v,
+// or copies the raw body if v implements io.Writer.
+func decodeBody(r io.Reader, u *url.URL, v interface{}) error {
+	if w, ok := v.(io.Writer); ok {
+		_, err := io.Copy(w, r)
+		return err
+	}
+
+	cb := u.Query().Get("callback")
+	if cb == "" {
+		return json.NewDecoder(r).Decode(v)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if data, err = stripJSONP(data, cb); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stripJSONP returns the JSON wrapped in a call of callback, e.g. cb({...});
+func stripJSONP(data []byte, callback string) ([]byte, error) {
+	b := bytes.TrimSpace(data)
+	b = bytes.TrimPrefix(b, []byte("/**/"))
+	b = bytes.TrimSpace(bytes.TrimSuffix(b, []byte(";")))
+
+	open := bytes.IndexByte(b, '(')
+	if open < 0 || len(b) == 0 || b[len(b)-1] != ')' {
+		// not wrapped at all
+		return data, nil
+	}
+	if name := string(bytes.TrimSpace(b[:open])); name != callback {
+		return nil, fmt.Errorf("dmm: JSONP callback is %q, expected %q", name, callback)
+	}
+	return b[open+1 : len(b)-1], nil
+}
@@ -0,0 +1,134 @@
+package dmm
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestItems_List_jsonp(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		if cb := r.URL.Query().Get("callback"); cb != "handle" {
+			t.Errorf("callback = %q, expected %q", cb, "handle")
+		}
+		fmt.Fprintf(w, "/**/ handle(%s);\n", testItemsRequest)
+	})
+
+	is, r, err := client.Items.List(ctx, &ItemOptions{Output: OutputJSON, Callback: "handle"})
+	if err != nil {
+		t.Fatalf("Items.List returned error: %v", err)
+	}
+	if len(is) != 2 || is[0].ContentID != "juy553" {
+		t.Errorf("Items.List returned %+v", is)
+	}
+	if r.TotalCount != 50000 {
+		t.Errorf("Response.TotalCount returned %d, expected %d", r.TotalCount, 50000)
+	}
+}
+
+func TestItems_List_jsonpError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `handle({"result":{"status":400,"message":"BAD REQUEST"}})`)
+	})
+
+	_, _, err := client.Items.List(ctx, &ItemOptions{Callback: "handle"})
+	er, ok := err.(*ErrorResponse)
+	if !ok {
+		t.Fatalf("Expected *ErrorResponse, got %#v", err)
+	}
+	if er.Result.Message != "BAD REQUEST" {
+		t.Errorf("ErrorResponse.Result.Message = %q, expected %q", er.Result.Message, "BAD REQUEST")
+	}
+}
+
+func TestItems_List_xmlRejected(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	})
+
+	for _, opt := range []*ItemOptions{
+		{Output: OutputXML},
+		{Output: OutputXML, Callback: "handle"},
+		{Output: "XML", Callback: "handle"},
+		{Output: "csv"},
+	} {
+		if _, _, err := client.Items.List(ctx, opt); err == nil {
+			t.Errorf("Items.List(%+v) expected error", opt)
+		} else if _, ok := err.(*OutputError); !ok {
+			t.Errorf("Items.List(%+v) returned %#v, expected *OutputError", opt, err)
+		}
+	}
+}
+
+func TestDo_rawWriter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	const body = `<?xml version="1.0" encoding="utf-8"?><response></response>`
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	})
+
+	req, _ := client.NewRequest(http.MethodGet, itemBasePath+"?output=xml", nil)
+	var buf bytes.Buffer
+	if _, err := client.Do(ctx, req, &buf); err != nil {
+		t.Fatalf("Client.Do returned error: %v", err)
+	}
+	if buf.String() != body {
+		t.Errorf("Client.Do wrote %q, expected %q", buf.String(), body)
+	}
+}
+
+func TestDo_rawWriterCallback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	})
+
+	for _, output := range []string{"xml", "XML"} {
+		req, _ := client.NewRequest(http.MethodGet, itemBasePath+"?output="+output+"&callback=handle", nil)
+		if _, err := client.Do(ctx, req, &bytes.Buffer{}); err == nil {
+			t.Errorf("Client.Do with output=%s and a callback returned no error", output)
+		} else if _, ok := err.(*OutputError); !ok {
+			t.Errorf("Client.Do with output=%s returned %#v, expected *OutputError", output, err)
+		}
+	}
+}
+
+func TestStripJSONP(t *testing.T) {
+	cases := []struct {
+		in       string
+		callback string
+		expected string
+		err      bool
+	}{
+		{`cb({"a":1})`, "cb", `{"a":1}`, false},
+		{" cb ( {\"a\":1} ) ;\n", "cb", ` {"a":1} `, false},
+		{`{"a":1}`, "cb", `{"a":1}`, false},
+		{`other({"a":1})`, "cb", ``, true},
+		{``, "cb", ``, false},
+	}
+	for _, c := range cases {
+		got, err := stripJSONP([]byte(c.in), c.callback)
+		if (err != nil) != c.err {
+			t.Errorf("stripJSONP(%q) returned error %v", c.in, err)
+			continue
+		}
+		if !c.err && string(got) != c.expected {
+			t.Errorf("stripJSONP(%q) = %q, expected %q", c.in, got, c.expected)
+		}
+	}
+}
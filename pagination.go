@@ -0,0 +1,76 @@
+package dmm
+
+import (
+	"context"
+)
+
+// PageFunc fetches a single page using the current state of the options given to Paginate.
+type PageFunc func(ctx context.Context) (*Response, error)
+
+// Paginate calls fn for every page of a list endpoint, advancing opt with Next between pages
+// until the last page has been fetched or fn returns an error.
+// opt must specify Hits, and should start from Offset 1 so that no entity is fetched twice.
+//
+// e.g.
+//
+//	opt := &ItemOptions{Site: SiteAdult, Hits: 100, Offset: 1}
+//	err := cli.Paginate(ctx, opt, func(ctx context.Context) (*Response, error) {
+//		is, r, err := cli.Items.List(ctx, opt)
+//		...
+//		return r, err
+//	})
+func (c *Client) Paginate(ctx context.Context, opt ListOptions, fn PageFunc) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := c.getTracer().Start(ctx, "dmm.paginate")
+	defer func() {
+		if err != nil {
+			span.SetAttributes(Attribute{AttrErrorType, ErrorType(err)})
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	for page := 1; ; page++ {
+		var resp *Response
+		if resp, err = c.page(ctx, page, opt, fn); err != nil {
+			return err
+		}
+		if isLastPage(opt, resp) {
+			span.SetAttributes(Attribute{AttrPage, page})
+			return nil
+		}
+		if err = opt.Next(); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) page(ctx context.Context, page int, opt ListOptions, fn PageFunc) (*Response, error) {
+	ctx, span := c.getTracer().Start(ctx, "dmm.page")
+	defer span.End()
+
+	span.SetAttributes(
+		Attribute{AttrPage, page},
+		Attribute{AttrHits, opt.GetHits()},
+		Attribute{AttrOffset, opt.GetOffset()},
+	)
+	resp, err := fn(ctx)
+	if err != nil {
+		span.SetAttributes(Attribute{AttrErrorType, ErrorType(err)})
+		span.RecordError(err)
+	}
+	return resp, err
+}
+
+// isLastPage reports whether no page follows resp
+func isLastPage(opt ListOptions, resp *Response) bool {
+	if resp == nil || resp.ResultCount == 0 {
+		return true
+	}
+	if h := opt.GetHits(); h > 0 && resp.ResultCount < h {
+		return true
+	}
+	return resp.FirstPosition+resp.ResultCount > resp.TotalCount
+}
@@ -0,0 +1,87 @@
+package dmm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testPagedItems serves total items named item1, item2, ... honouring hits and offset
+func testPagedItems(t *testing.T, total int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hits, _ := strconv.Atoi(r.URL.Query().Get("hits"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if hits == 0 {
+			hits = 20
+		}
+		if offset == 0 {
+			offset = 1
+		}
+		var items []string
+		for i := offset; i < offset+hits && i <= total; i++ {
+			items = append(items, fmt.Sprintf(`{"content_id":"item%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"request":{"parameters":{"hits":"%d","offset":"%d"}},"result":{"status":200,"result_count":%d,"total_count":%d,"first_position":%d,"items":[%s]}}`,
+			hits, offset, len(items), total, offset, strings.Join(items, ","))
+	}
+}
+
+func TestClient_Paginate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, testPagedItems(t, 7))
+
+	opt := &ItemOptions{Hits: 3, Offset: 1}
+	var actual []string
+	err := client.Paginate(ctx, opt, func(ctx context.Context) (*Response, error) {
+		is, r, err := client.Items.List(ctx, opt)
+		for _, i := range is {
+			actual = append(actual, i.ContentID)
+		}
+		return r, err
+	})
+	if err != nil {
+		t.Fatalf("Client.Paginate returned error: %v", err)
+	}
+
+	expected := []string{"item1", "item2", "item3", "item4", "item5", "item6", "item7"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Client.Paginate fetched %v, expected %v", actual, expected)
+	}
+}
+
+func TestClient_Paginate_exactPages(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, testPagedItems(t, 6))
+
+	opt := &ItemOptions{Hits: 3, Offset: 1}
+	pages := 0
+	err := client.Paginate(ctx, opt, func(ctx context.Context) (*Response, error) {
+		pages++
+		_, r, err := client.Items.List(ctx, opt)
+		return r, err
+	})
+	if err != nil {
+		t.Fatalf("Client.Paginate returned error: %v", err)
+	}
+	if pages != 2 {
+		t.Errorf("Client.Paginate fetched %d pages, expected 2", pages)
+	}
+}
+
+func TestClient_Paginate_error(t *testing.T) {
+	expected := fmt.Errorf("failed")
+	err := NewClient(nil).Paginate(ctx, &ItemOptions{Hits: 3}, func(ctx context.Context) (*Response, error) {
+		return nil, expected
+	})
+	if err != expected {
+		t.Errorf("Client.Paginate returned error %v, expected %v", err, expected)
+	}
+}
@@ -0,0 +1,55 @@
+package pricetrack
+
+import (
+	"context"
+	"sync"
+)
+
+// Store keeps the snapshot history of items
+type Store interface {
+	// Latest returns the most recent snapshot of the item
+	Latest(ctx context.Context, contentID string) (Snapshot, bool, error)
+	// Append adds a snapshot to the history of its item
+	Append(ctx context.Context, s Snapshot) error
+	// History returns every snapshot of the item, oldest first
+	History(ctx context.Context, contentID string) ([]Snapshot, error)
+}
+
+// MemoryStore is a Store keeping snapshots in memory
+type MemoryStore struct {
+	mu        sync.RWMutex
+	snapshots map[string][]Snapshot
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snapshots: map[string][]Snapshot{}}
+}
+
+// Latest returns the most recent snapshot of the item
+func (m *MemoryStore) Latest(_ context.Context, contentID string) (Snapshot, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ss := m.snapshots[contentID]
+	if len(ss) == 0 {
+		return Snapshot{}, false, nil
+	}
+	return ss[len(ss)-1], true, nil
+}
+
+// Append adds a snapshot to the history of its item
+func (m *MemoryStore) Append(_ context.Context, s Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots[s.ContentID] = append(m.snapshots[s.ContentID], s)
+	return nil
+}
+
+// History returns every snapshot of the item, oldest first
+func (m *MemoryStore) History(_ context.Context, contentID string) ([]Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Snapshot(nil), m.snapshots[contentID]...), nil
+}
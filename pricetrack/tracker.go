@@ -0,0 +1,228 @@
+// Package pricetrack records the prices, stock and reviews of items over time
+// and reports their changes as typed events, e.g. to alert on sales.
+//
+//	t := pricetrack.New(pricetrack.NewMemoryStore())
+//	events, err := t.Poll(ctx, cli, &dmm.ItemOptions{Site: dmm.SiteAdult, Floor: "videoa", Hits: 100, Offset: 1})
+//	for _, e := range events {
+//		if e.Type == pricetrack.EventPriceDrop {
+//			...
+//		}
+//	}
+package pricetrack
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/usk81/go-dmm"
+)
+
+// EventType is the kind of a change
+type EventType string
+
+// Event types
+const (
+	// EventNewItem is emitted the first time an item is observed
+	EventNewItem EventType = "new_item"
+	// EventPriceDrop is emitted when Prices.Price, or the price of a delivery, falls
+	EventPriceDrop EventType = "price_drop"
+	// EventPriceRise is emitted when Prices.Price, or the price of a delivery, rises
+	EventPriceRise EventType = "price_rise"
+	// EventPriceChange is emitted when a price changes to or from a value which is not a number,
+	// or becomes or stops being a "from" price like "300~" of the same amount
+	EventPriceChange EventType = "price_change"
+	// EventListPriceChange is emitted when Prices.ListPrice changes
+	EventListPriceChange EventType = "list_price_change"
+	// EventNewDelivery is emitted when a delivery type becomes available
+	EventNewDelivery EventType = "new_delivery"
+	// EventDeliveryRemoved is emitted when a delivery type is no longer available
+	EventDeliveryRemoved EventType = "delivery_removed"
+	// EventStockChange is emitted when Item.Stock changes
+	EventStockChange EventType = "stock_change"
+	// EventReviewCountChange is emitted when Review.Count changes
+	EventReviewCountChange EventType = "review_count_change"
+	// EventReviewAverageChange is emitted when Review.Average changes
+	EventReviewAverageChange EventType = "review_average_change"
+)
+
+// Snapshot is the state of an item at a point in time
+type Snapshot struct {
+	ContentID     string            `json:"content_id"`
+	Time          time.Time         `json:"time"`
+	Price         string            `json:"price"`
+	ListPrice     string            `json:"list_price"`
+	Deliveries    map[string]string `json:"deliveries,omitempty"`
+	Stock         string            `json:"stock"`
+	ReviewCount   int               `json:"review_count"`
+	ReviewAverage string            `json:"review_average"`
+}
+
+// Event is a change of an item between two snapshots
+type Event struct {
+	Type      EventType `json:"type"`
+	ContentID string    `json:"content_id"`
+	// Delivery is the delivery type for delivery related events
+	Delivery string    `json:"delivery,omitempty"`
+	Old      string    `json:"old,omitempty"`
+	New      string    `json:"new,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Tracker takes snapshots of items and detects their changes
+type Tracker struct {
+	Store Store
+	// Now returns the time of snapshots; time.Now is used if nil
+	Now func() time.Time
+}
+
+// New returns a Tracker keeping its history in store
+func New(store Store) *Tracker {
+	return &Tracker{Store: store}
+}
+
+// NewSnapshot returns the snapshot of i at t
+func NewSnapshot(i dmm.Item, t time.Time) Snapshot {
+	s := Snapshot{
+		ContentID:     i.ContentID,
+		Time:          t,
+		Price:         i.Prices.Price,
+		ListPrice:     i.Prices.ListPrice,
+		Stock:         i.Stock,
+		ReviewCount:   i.Review.Count,
+		ReviewAverage: i.Review.Average,
+	}
+	if ds := i.Prices.Deliveries.Delivery; len(ds) > 0 {
+		s.Deliveries = make(map[string]string, len(ds))
+		for _, d := range ds {
+			s.Deliveries[d.Type] = d.Price
+		}
+	}
+	return s
+}
+
+// Observe compares items with their latest snapshots and returns the changes.
+// A snapshot is only stored for new or changed items, so the history holds one entry per change.
+func (t *Tracker) Observe(ctx context.Context, items ...dmm.Item) ([]Event, error) {
+	now := t.now()
+	var es []Event
+	for _, i := range items {
+		s := NewSnapshot(i, now)
+		prev, ok, err := t.Store.Latest(ctx, i.ContentID)
+		if err != nil {
+			return es, err
+		}
+		var changes []Event
+		if ok {
+			changes = Compare(prev, s)
+		} else {
+			changes = []Event{{Type: EventNewItem, ContentID: s.ContentID, New: s.Price, Time: now}}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if err := t.Store.Append(ctx, s); err != nil {
+			return es, err
+		}
+		es = append(es, changes...)
+	}
+	return es, nil
+}
+
+// Poll pages through the items matching opt and observes every page.
+// opt must specify Hits; see dmm.Client.Paginate.
+func (t *Tracker) Poll(ctx context.Context, cli *dmm.Client, opt *dmm.ItemOptions) ([]Event, error) {
+	var es []Event
+	err := cli.Paginate(ctx, opt, func(ctx context.Context) (*dmm.Response, error) {
+		is, r, err := cli.Items.List(ctx, opt)
+		if err != nil {
+			return r, err
+		}
+		pes, err := t.Observe(ctx, is...)
+		es = append(es, pes...)
+		return r, err
+	})
+	return es, err
+}
+
+func (t *Tracker) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+// Compare returns the changes from prev to cur
+func Compare(prev, cur Snapshot) []Event {
+	var es []Event
+	ev := func(typ EventType, delivery, from, to string) {
+		es = append(es, Event{Type: typ, ContentID: cur.ContentID, Delivery: delivery, Old: from, New: to, Time: cur.Time})
+	}
+
+	if typ, ok := priceChange(prev.Price, cur.Price); ok {
+		ev(typ, "", prev.Price, cur.Price)
+	}
+	if prev.ListPrice != cur.ListPrice {
+		ev(EventListPriceChange, "", prev.ListPrice, cur.ListPrice)
+	}
+
+	types := make([]string, 0, len(cur.Deliveries))
+	for d := range cur.Deliveries {
+		types = append(types, d)
+	}
+	sort.Strings(types)
+	for _, d := range types {
+		old, ok := prev.Deliveries[d]
+		if !ok {
+			ev(EventNewDelivery, d, "", cur.Deliveries[d])
+			continue
+		}
+		if typ, ok := priceChange(old, cur.Deliveries[d]); ok {
+			ev(typ, d, old, cur.Deliveries[d])
+		}
+	}
+	types = types[:0]
+	for d := range prev.Deliveries {
+		if _, ok := cur.Deliveries[d]; !ok {
+			types = append(types, d)
+		}
+	}
+	sort.Strings(types)
+	for _, d := range types {
+		ev(EventDeliveryRemoved, d, prev.Deliveries[d], "")
+	}
+
+	if prev.Stock != cur.Stock {
+		ev(EventStockChange, "", prev.Stock, cur.Stock)
+	}
+	if prev.ReviewCount != cur.ReviewCount {
+		ev(EventReviewCountChange, "", strconv.Itoa(prev.ReviewCount), strconv.Itoa(cur.ReviewCount))
+	}
+	if prev.ReviewAverage != cur.ReviewAverage {
+		ev(EventReviewAverageChange, "", prev.ReviewAverage, cur.ReviewAverage)
+	}
+	return es
+}
+
+// priceChange reports whether a price fell, rose or changed otherwise.
+// Prices differing only in their formatting, e.g. "1980" and "1,980", are the same.
+func priceChange(from, to string) (EventType, bool) {
+	if from == to {
+		return "", false
+	}
+	o, ofrom, oerr := dmm.ParsePrice(from)
+	n, nfrom, nerr := dmm.ParsePrice(to)
+	if oerr != nil || nerr != nil {
+		return EventPriceChange, true
+	}
+	switch {
+	case n < o:
+		return EventPriceDrop, true
+	case n > o:
+		return EventPriceRise, true
+	case ofrom != nfrom:
+		return EventPriceChange, true
+	}
+	return "", false
+}
@@ -0,0 +1,143 @@
+package pricetrack
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+func testItem(price string, deliveries map[string]string, stock string, reviews int) dmm.Item {
+	i := dmm.Item{
+		ContentID: "juy00553",
+		Prices:    dmm.Prices{Price: price, ListPrice: "3218"},
+		Stock:     stock,
+		Review:    dmm.Review{Count: reviews, Average: "4.00"},
+	}
+	for _, typ := range []string{"stream", "download", "hd"} {
+		if p, ok := deliveries[typ]; ok {
+			i.Prices.Deliveries.Delivery = append(i.Prices.Deliveries.Delivery, dmm.Delivery{Type: typ, Price: p})
+		}
+	}
+	return i
+}
+
+func TestTracker_Observe(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := &Tracker{Store: store, Now: func() time.Time { return now }}
+
+	es, err := tr.Observe(ctx, testItem("2,381", map[string]string{"stream": "300"}, "stock", 1))
+	if err != nil {
+		t.Fatalf("Tracker.Observe returned error: %v", err)
+	}
+	expected := []Event{{Type: EventNewItem, ContentID: "juy00553", New: "2,381", Time: now}}
+	if !reflect.DeepEqual(es, expected) {
+		t.Errorf("Tracker.Observe returned %+v, expected %+v", es, expected)
+	}
+
+	now = now.Add(time.Hour)
+	if es, _ = tr.Observe(ctx, testItem("2,381", map[string]string{"stream": "300"}, "stock", 1)); len(es) != 0 {
+		t.Errorf("Tracker.Observe returned %+v for an unchanged item", es)
+	}
+
+	now = now.Add(time.Hour)
+	es, err = tr.Observe(ctx, testItem("1980~", map[string]string{"stream": "200", "download": "980"}, "reserve", 3))
+	if err != nil {
+		t.Fatalf("Tracker.Observe returned error: %v", err)
+	}
+	expected = []Event{
+		{Type: EventPriceDrop, ContentID: "juy00553", Old: "2,381", New: "1980~", Time: now},
+		{Type: EventNewDelivery, ContentID: "juy00553", Delivery: "download", New: "980", Time: now},
+		{Type: EventPriceDrop, ContentID: "juy00553", Delivery: "stream", Old: "300", New: "200", Time: now},
+		{Type: EventStockChange, ContentID: "juy00553", Old: "stock", New: "reserve", Time: now},
+		{Type: EventReviewCountChange, ContentID: "juy00553", Old: "1", New: "3", Time: now},
+	}
+	if !reflect.DeepEqual(es, expected) {
+		t.Errorf("Tracker.Observe returned %+v, expected %+v", es, expected)
+	}
+
+	now = now.Add(time.Hour)
+	es, _ = tr.Observe(ctx, testItem("2500", map[string]string{"download": "980"}, "reserve", 3))
+	expected = []Event{
+		{Type: EventPriceRise, ContentID: "juy00553", Old: "1980~", New: "2500", Time: now},
+		{Type: EventDeliveryRemoved, ContentID: "juy00553", Delivery: "stream", Old: "200", Time: now},
+	}
+	if !reflect.DeepEqual(es, expected) {
+		t.Errorf("Tracker.Observe returned %+v, expected %+v", es, expected)
+	}
+
+	now = now.Add(time.Hour)
+	i := testItem("2500", map[string]string{"download": "980"}, "reserve", 3)
+	i.Review.Average = "4.50"
+	es, _ = tr.Observe(ctx, i)
+	expected = []Event{{Type: EventReviewAverageChange, ContentID: "juy00553", Old: "4.00", New: "4.50", Time: now}}
+	if !reflect.DeepEqual(es, expected) {
+		t.Errorf("Tracker.Observe returned %+v, expected %+v", es, expected)
+	}
+
+	h, err := store.History(ctx, "juy00553")
+	if err != nil {
+		t.Fatalf("MemoryStore.History returned error: %v", err)
+	}
+	if len(h) != 4 || h[3].ReviewAverage != "4.50" {
+		t.Errorf("history has %d snapshots, expected 4 ending with the new review average", len(h))
+	}
+}
+
+func TestTracker_Poll(t *testing.T) {
+	server := dmmtest.NewServer()
+	defer server.Close()
+	server.Items = []dmm.Item{
+		testItem("1000", nil, "", 0),
+		{ContentID: "other", Prices: dmm.Prices{Price: "500"}},
+	}
+
+	ctx := context.Background()
+	cli := server.NewClient()
+	tr := New(NewMemoryStore())
+	es, err := tr.Poll(ctx, cli, &dmm.ItemOptions{Hits: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("Tracker.Poll returned error: %v", err)
+	}
+	if len(es) != 2 {
+		t.Errorf("Tracker.Poll returned %d events, expected 2", len(es))
+	}
+
+	server.Items[0].Prices.Price = "800"
+	es, err = tr.Poll(ctx, cli, &dmm.ItemOptions{Hits: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("Tracker.Poll returned error: %v", err)
+	}
+	if len(es) != 1 || es[0].Type != EventPriceDrop || es[0].New != "800" {
+		t.Errorf("Tracker.Poll returned %+v", es)
+	}
+}
+
+func TestCompare_fromPrice(t *testing.T) {
+	es := Compare(Snapshot{Price: "300〜"}, Snapshot{Price: "200〜"})
+	if len(es) != 1 || es[0].Type != EventPriceDrop {
+		t.Errorf("Compare returned %+v for a falling from price, expected a price drop", es)
+	}
+}
+
+func TestCompare_fromFlag(t *testing.T) {
+	cases := []struct {
+		old, new string
+		events   int
+	}{
+		{"300~", "300", 1},
+		{"300", "300〜", 1},
+		{"1980", "1,980", 0},
+	}
+	for _, c := range cases {
+		es := Compare(Snapshot{Price: c.old}, Snapshot{Price: c.new})
+		if len(es) != c.events || (c.events > 0 && es[0].Type != EventPriceChange) {
+			t.Errorf("Compare of %q and %q returned %+v, expected %d price changes", c.old, c.new, es, c.events)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package proxy serves read-only endpoints of the DMM Affiliate API over HTTP,
+// so that front-ends can search without holding the API ID.
+//
+//	cli, _ := dmm.New(nil, dmm.SetCache(dmm.NewMemoryCache(), 5*time.Minute), dmm.SetRateLimit(dmm.NewRateLimiter(1, 5)))
+//	http.Handle("/api/", http.StripPrefix("/api", proxy.NewHandler(cli, apiID, affiliateID)))
+//
+// The endpoints are /items, /actresses, /genres, /makers, /series, /authors and /floors.
+// Query parameters are the API parameters of the corresponding options, e.g.
+// /items?site=FANZA&service=digital&floor=videoa&keyword=...&hits=10.
+// Credentials, output and callback can not be set by clients.
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/internal/params"
+)
+
+// DefaultMaxHits is the largest hits parameter a client may request
+const DefaultMaxHits = 100
+
+// StatusClientClosedRequest is the status logged for requests whose client went away before the response
+const StatusClientClosedRequest = 499
+
+// Handler is an http.Handler proxying the DMM API with server-side credentials.
+// Caching and rate limiting are applied by the middleware of Client.
+type Handler struct {
+	Client      *dmm.Client
+	APIID       string
+	AffiliateID string
+
+	// MaxHits limits the hits parameter. DefaultMaxHits is used if it is 0.
+	MaxHits int
+
+	once sync.Once
+	mux  *http.ServeMux
+}
+
+var _ http.Handler = &Handler{}
+
+// List is the normalized JSON body of a successful response
+type List struct {
+	ResultCount   int         `json:"result_count"`
+	TotalCount    int         `json:"total_count"`
+	FirstPosition int         `json:"first_position"`
+	Results       interface{} `json:"results"`
+}
+
+// Error is the normalized JSON body of a failed request
+type Error struct {
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+type errorBody struct {
+	Error Error `json:"error"`
+}
+
+// endpoint lists entities of one API with options decoded from the query
+type endpoint struct {
+	options func() interface{}
+	list    func(context.Context, *dmm.Client, interface{}) (interface{}, *dmm.Response, error)
+}
+
+var endpoints = map[string]endpoint{
+	"/items": {
+		options: func() interface{} { return &dmm.ItemOptions{} },
+		list: func(ctx context.Context, c *dmm.Client, opt interface{}) (interface{}, *dmm.Response, error) {
+			vs, r, err := c.Items.List(ctx, opt.(*dmm.ItemOptions))
+			if vs == nil {
+				vs = []dmm.Item{}
+			}
+			return vs, r, err
+		},
+	},
+	"/actresses": {
+		options: func() interface{} { return &dmm.ActressOptions{} },
+		list: func(ctx context.Context, c *dmm.Client, opt interface{}) (interface{}, *dmm.Response, error) {
+			vs, r, err := c.Actresses.List(ctx, opt.(*dmm.ActressOptions))
+			if vs == nil {
+				vs = []dmm.Actress{}
+			}
+			return vs, r, err
+		},
+	},
+	"/genres": {
+		options: func() interface{} { return &dmm.GenreOptions{} },
+		list: func(ctx context.Context, c *dmm.Client, opt interface{}) (interface{}, *dmm.Response, error) {
+			vs, r, err := c.Genres.List(ctx, opt.(*dmm.GenreOptions))
+			if vs == nil {
+				vs = []dmm.Genre{}
+			}
+			return vs, r, err
+		},
+	},
+	"/makers": {
+		options: func() interface{} { return &dmm.MakerOptions{} },
+		list: func(ctx context.Context, c *dmm.Client, opt interface{}) (interface{}, *dmm.Response, error) {
+			vs, r, err := c.Makers.List(ctx, opt.(*dmm.MakerOptions))
+			if vs == nil {
+				vs = []dmm.Maker{}
+			}
+			return vs, r, err
+		},
+	},
+	"/series": {
+		options: func() interface{} { return &dmm.SeriesOptions{} },
+		list: func(ctx context.Context, c *dmm.Client, opt interface{}) (interface{}, *dmm.Response, error) {
+			vs, r, err := c.Series.List(ctx, opt.(*dmm.SeriesOptions))
+			if vs == nil {
+				vs = []dmm.Series{}
+			}
+			return vs, r, err
+		},
+	},
+	"/authors": {
+		options: func() interface{} { return &dmm.AuthorOptions{} },
+		list: func(ctx context.Context, c *dmm.Client, opt interface{}) (interface{}, *dmm.Response, error) {
+			vs, r, err := c.Authors.List(ctx, opt.(*dmm.AuthorOptions))
+			if vs == nil {
+				vs = []dmm.Author{}
+			}
+			return vs, r, err
+		},
+	},
+	"/floors": {
+		options: func() interface{} { return &dmm.FloorOptions{} },
+		list: func(ctx context.Context, c *dmm.Client, opt interface{}) (interface{}, *dmm.Response, error) {
+			vs, r, err := c.Floors.List(ctx, opt.(*dmm.FloorOptions))
+			if vs == nil {
+				vs = []dmm.Site{}
+			}
+			return vs, r, err
+		},
+	},
+}
+
+// NewHandler returns a Handler sending requests with c and the given credentials
+func NewHandler(c *dmm.Client, apiID, affiliateID string) *Handler {
+	return &Handler{Client: c, APIID: apiID, AffiliateID: affiliateID}
+}
+
+// ServeHTTP answers GET and HEAD requests to the endpoints
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, Error{Status: http.StatusMethodNotAllowed, Message: "method not allowed"})
+		return
+	}
+	h.once.Do(h.route)
+	h.mux.ServeHTTP(w, r)
+}
+
+// route builds the mux of the endpoints
+func (h *Handler) route() {
+	h.mux = http.NewServeMux()
+	for path, e := range endpoints {
+		h.mux.Handle(path, h.serve(e))
+	}
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, Error{Status: http.StatusNotFound, Message: "unknown endpoint " + r.URL.Path})
+	})
+}
+
+func (h *Handler) serve(e endpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		opt := e.options()
+		if errs := decodeOptions(r.URL.Query(), opt, h.maxHits()); len(errs) > 0 {
+			writeError(w, Error{Status: http.StatusBadRequest, Message: "invalid parameters", Errors: errs})
+			return
+		}
+		params.SetCredentials(opt, h.APIID, h.AffiliateID)
+
+		vs, resp, err := e.list(r.Context(), h.Client, opt)
+		if err != nil {
+			writeError(w, h.upstreamError(err))
+			return
+		}
+		l := List{Results: vs}
+		if resp != nil {
+			l.ResultCount, l.TotalCount, l.FirstPosition = resp.ResultCount, resp.TotalCount, resp.FirstPosition
+		}
+		writeJSON(w, http.StatusOK, l)
+	}
+}
+
+func (h *Handler) maxHits() int {
+	if h.MaxHits > 0 {
+		return h.MaxHits
+	}
+	return DefaultMaxHits
+}
+
+// decodeOptions sets the fields of opt, a pointer to an options struct, from q.
+// It returns the invalid parameters with the reason.
+func decodeOptions(q map[string][]string, opt interface{}, maxHits int) map[string]string {
+	errs := map[string]string{}
+	fields := map[string]reflect.Value{}
+	v := reflect.ValueOf(opt).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name := params.Name(t.Field(i)); name != "" && !params.Reserved[name] {
+			fields[name] = v.Field(i)
+		}
+	}
+
+	for name, vs := range q {
+		f, ok := fields[name]
+		if !ok {
+			errs[name] = "unknown parameter"
+			continue
+		}
+		if len(vs) != 1 {
+			errs[name] = "must be given once"
+			continue
+		}
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(vs[0])
+		case reflect.Int:
+			n, err := strconv.Atoi(vs[0])
+			if err != nil || n < 0 {
+				errs[name] = "must be a non-negative integer"
+				continue
+			}
+			if name == "hits" && n > maxHits {
+				errs[name] = fmt.Sprintf("must be %d or less", maxHits)
+				continue
+			}
+			f.SetInt(int64(n))
+		}
+	}
+	return errs
+}
+
+// upstreamError converts an API error. Only API error messages are passed on,
+// since other errors may contain the request URL with the credentials.
+func (h *Handler) upstreamError(err error) Error {
+	if er, ok := err.(*dmm.ErrorResponse); ok && er.Response != nil {
+		status := http.StatusBadGateway
+		if s := er.Response.StatusCode; s >= 400 && s < 500 {
+			status = s
+		}
+		e := Error{Status: status, Message: h.redact(er.Result.Message)}
+		for k, v := range er.Result.Errors {
+			if e.Errors == nil {
+				e.Errors = map[string]string{}
+			}
+			e.Errors[k] = h.redact(v)
+		}
+		return e
+	}
+	// transport errors wrap the context's error
+	if ue, ok := err.(*url.Error); ok {
+		err = ue.Err
+	}
+	switch err {
+	case context.Canceled:
+		return Error{Status: StatusClientClosedRequest, Message: "request canceled"}
+	case context.DeadlineExceeded:
+		return Error{Status: http.StatusGatewayTimeout, Message: "upstream request timed out"}
+	}
+	return Error{Status: http.StatusBadGateway, Message: "upstream request failed"}
+}
+
+// redact masks the credentials within s
+func (h *Handler) redact(s string) string {
+	for _, c := range []string{h.APIID, h.AffiliateID} {
+		if c != "" {
+			s = strings.Replace(s, c, "REDACTED", -1)
+		}
+	}
+	return s
+}
+
+func writeError(w http.ResponseWriter, e Error) {
+	writeJSON(w, e.Status, errorBody{e})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
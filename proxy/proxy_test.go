@@ -0,0 +1,167 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/usk81/go-dmm"
+	"github.com/usk81/go-dmm/dmmtest"
+)
+
+type response struct {
+	List
+	Error *Error `json:"error"`
+}
+
+func testHandler(t *testing.T) (*dmmtest.Server, *Handler) {
+	s := dmmtest.NewServer()
+	s.Items = []dmm.Item{
+		{ContentID: "abc00001", ServiceCode: "digital", FloorCode: "videoa", Title: "first"},
+		{ContentID: "abc00002", ServiceCode: "digital", FloorCode: "videoa", Title: "second"},
+		{ContentID: "xyz00001", ServiceCode: "mono", FloorCode: "dvd", Title: "third"},
+	}
+	s.Actresses = []dmm.Actress{{ID: "1011199", Name: "桃乃木かな", Ruby: "もものぎかな"}}
+	s.MaxOffset = 50
+	return s, NewHandler(s.NewClient(dmm.SetCache(dmm.NewMemoryCache(), time.Minute)), "api-1234", "aff-990")
+}
+
+func get(t *testing.T, h http.Handler, method, target string) (*httptest.ResponseRecorder, response) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	var r response
+	if err := json.Unmarshal(w.Body.Bytes(), &r); err != nil {
+		t.Fatalf("%s %s returned invalid JSON: %v\n%s", method, target, err, w.Body)
+	}
+	return w, r
+}
+
+func TestHandler_items(t *testing.T) {
+	server, h := testHandler(t)
+	defer server.Close()
+
+	w, r := get(t, h, http.MethodGet, "/items?site=FANZA&service=digital&hits=1")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("GET /items returned %d %s:\n%s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	if r.TotalCount != 2 || r.ResultCount != 1 || r.FirstPosition != 1 {
+		t.Errorf("GET /items returned counts %+v", r.List)
+	}
+	items, _ := r.Results.([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["content_id"] != "abc00001" {
+		t.Errorf("GET /items returned results %v", r.Results)
+	}
+
+	req := server.Requests()[0]
+	for _, p := range []string{"api_id=api-1234", "affiliate_id=aff-990", "site=FANZA", "service=digital", "hits=1"} {
+		if !strings.Contains(req, p) {
+			t.Errorf("GET /items requested %s, expected %s", req, p)
+		}
+	}
+
+	get(t, h, http.MethodGet, "/items?site=FANZA&service=digital&hits=1")
+	if n := server.RequestCount("ItemList"); n != 1 {
+		t.Errorf("sent %d requests, expected the client cache to answer", n)
+	}
+}
+
+func TestHandler_emptyResults(t *testing.T) {
+	server, h := testHandler(t)
+	defer server.Close()
+
+	w, _ := get(t, h, http.MethodGet, "/actresses?keyword=nobody")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"results":[]`) {
+		t.Errorf("GET /actresses returned %d:\n%s", w.Code, w.Body)
+	}
+}
+
+func TestHandler_invalid(t *testing.T) {
+	server, h := testHandler(t)
+	defer server.Close()
+
+	tests := []struct {
+		method, target string
+		status         int
+		errors         []string
+	}{
+		{http.MethodGet, "/items?api_id=mine&hits=1", http.StatusBadRequest, []string{"api_id"}},
+		{http.MethodGet, "/items?callback=cb&output=xml", http.StatusBadRequest, []string{"callback", "output"}},
+		{http.MethodGet, "/items?hits=101&offset=x&foo=1", http.StatusBadRequest, []string{"hits", "offset", "foo"}},
+		{http.MethodGet, "/items?site=a&site=b", http.StatusBadRequest, []string{"site"}},
+		{http.MethodGet, "/floors?site=FANZA", http.StatusBadRequest, []string{"site"}},
+		{http.MethodGet, "/items?offset=51", http.StatusBadRequest, []string{"offset"}},
+		{http.MethodGet, "/nothing", http.StatusNotFound, nil},
+		{http.MethodPost, "/items", http.StatusMethodNotAllowed, nil},
+	}
+	for _, tt := range tests {
+		w, r := get(t, h, tt.method, tt.target)
+		if w.Code != tt.status || r.Error == nil || r.Error.Status != tt.status {
+			t.Errorf("%s %s returned %d:\n%s", tt.method, tt.target, w.Code, w.Body)
+			continue
+		}
+		for _, e := range tt.errors {
+			if _, ok := r.Error.Errors[e]; !ok {
+				t.Errorf("%s %s returned errors %v, expected %s", tt.method, tt.target, r.Error.Errors, e)
+			}
+		}
+		if strings.Contains(w.Body.String(), "api-1234") {
+			t.Errorf("%s %s leaked the API ID:\n%s", tt.method, tt.target, w.Body)
+		}
+	}
+	if n := server.RequestCount("ItemList"); n != 1 {
+		t.Errorf("sent %d requests, expected only valid parameters to be requested", n)
+	}
+}
+
+func TestHandler_upstreamFailure(t *testing.T) {
+	server, h := testHandler(t)
+	server.Close()
+
+	w, r := get(t, h, http.MethodGet, "/items")
+	if w.Code != http.StatusBadGateway || r.Error == nil || strings.Contains(w.Body.String(), "api-1234") {
+		t.Errorf("GET /items returned %d:\n%s", w.Code, w.Body)
+	}
+}
+
+func TestHandler_canceled(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+	cli, err := dmm.New(nil, dmm.SetBaseURL(upstream.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(cli, "api-1234", "aff-990")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil).WithContext(ctx))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("GET /items past its deadline returned %d, expected %d:\n%s", w.Code, http.StatusGatewayTimeout, w.Body)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil).WithContext(ctx))
+	if w.Code != StatusClientClosedRequest {
+		t.Errorf("GET /items of a gone client returned %d, expected %d:\n%s", w.Code, StatusClientClosedRequest, w.Body)
+	}
+}
+
+func TestHandler_literal(t *testing.T) {
+	server, _ := testHandler(t)
+	defer server.Close()
+	h := &Handler{Client: server.NewClient(), APIID: "api-1234", AffiliateID: "aff-990"}
+
+	w, r := get(t, h, http.MethodGet, "/items?site=FANZA&hits=1")
+	if w.Code != http.StatusOK || r.ResultCount != 1 {
+		t.Errorf("GET /items returned %d:\n%s", w.Code, w.Body)
+	}
+}
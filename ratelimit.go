@@ -0,0 +1,106 @@
+package dmm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting the rate of API calls.
+// It is safe for concurrent use and may be shared by several clients.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing rate calls per second on average,
+// and bursts of up to burst calls. A burst less than 1 is treated as 1.
+// A rate of 0 or less does not limit calls.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Duration(float64(time.Second) / rate)
+	}
+	return &RateLimiter{
+		interval: interval,
+		burst:    burst,
+		tokens:   float64(burst),
+		now:      time.Now,
+	}
+}
+
+// SetRateLimit is a client option for limiting the rate of API calls with l.
+func SetRateLimit(l *RateLimiter) ClientOpt {
+	return UseMiddleware(RateLimitMiddleware(l))
+}
+
+// RateLimitMiddleware returns a Middleware waiting for l before every API call.
+// The call fails with the context's error if ctx is done first.
+func RateLimitMiddleware(l *RateLimiter) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			if err := l.Wait(ctx); err != nil {
+				return nil, err
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// Wait blocks until a call is allowed or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	d := l.reserve()
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token and returns how long to wait until it is available
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.interval <= 0 {
+		return 0
+	}
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// cancel returns a token taken by a call which gave up waiting
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.interval > 0 {
+		l.tokens++
+	}
+}
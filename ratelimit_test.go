@@ -0,0 +1,55 @@
+package dmm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_reserve(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(2, 2)
+	l.now = func() time.Time { return now }
+
+	for i, expected := range []time.Duration{0, 0, 500 * time.Millisecond, time.Second} {
+		if d := l.reserve(); d != expected {
+			t.Errorf("reserve #%d waits %v, expected %v", i, d, expected)
+		}
+	}
+	now = now.Add(10 * time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("reserve after a pause waits %v, expected 0", d)
+	}
+}
+
+func TestRateLimiter_unlimited(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		l := NewRateLimiter(rate, 1)
+		for i := 0; i < 3; i++ {
+			if d := l.reserve(); d != 0 {
+				t.Errorf("reserve #%d of rate %v waits %v, expected 0", i, rate, d)
+			}
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testItemsRequest)
+	})
+	client.Use(RateLimitMiddleware(NewRateLimiter(0.001, 1)))
+
+	if _, _, err := client.Items.List(ctx, nil); err != nil {
+		t.Fatalf("Items.List returned error: %v", err)
+	}
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := client.Items.List(c, nil); err != context.DeadlineExceeded {
+		t.Errorf("Items.List returned error %v, expected %v", err, context.DeadlineExceeded)
+	}
+}
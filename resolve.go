@@ -0,0 +1,65 @@
+package dmm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/usk81/go-dmm/kana"
+)
+
+// ResolveThreshold is the lowest kana.Match score of an entity accepted by the Resolve functions
+const ResolveThreshold = 0.75
+
+// resolveHits is the page size of the lists searched by the Resolve functions
+const resolveHits = 100
+
+// ErrNotResolved is returned by the Resolve functions if no entity matches the name
+var ErrNotResolved = errors.New("dmm: no entity matches the name")
+
+// errResolved stops the pagination of a Resolve function at an exact match
+var errResolved = errors.New("dmm: resolved")
+
+// matcher keeps the best score of the entities seen by a Resolve function
+type matcher struct {
+	name  string
+	score float64
+}
+
+// consider reports whether an entity known by names matches better than every entity before it
+func (m *matcher) consider(names ...string) bool {
+	s := kana.Match(m.name, names...)
+	if s < ResolveThreshold || s <= m.score {
+		return false
+	}
+	m.score = s
+	return true
+}
+
+// resolve pages through opt with fn, which passes the entities of each page to m,
+// until the last page or an exact match
+func (c *Client) resolve(ctx context.Context, opt ListOptions, m *matcher, fn PageFunc) error {
+	err := c.Paginate(ctx, opt, func(ctx context.Context) (*Response, error) {
+		r, err := fn(ctx)
+		if err == nil && m.score == 1 {
+			return r, errResolved
+		}
+		return r, err
+	})
+	if err == errResolved {
+		return nil
+	}
+	if err == nil && m.score == 0 {
+		return ErrNotResolved
+	}
+	return err
+}
+
+// resolvePage sets the page of the first request of a Resolve function
+func resolvePage(hits, offset *int) {
+	if *hits == 0 {
+		*hits = resolveHits
+	}
+	if *offset == 0 {
+		*offset = 1
+	}
+}
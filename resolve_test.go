@@ -0,0 +1,57 @@
+package dmm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMatcher_consider(t *testing.T) {
+	m := &matcher{name: "みかみゆあ"}
+	if m.consider("別人", "べつじん") {
+		t.Error("matcher.consider accepted a name below the threshold")
+	}
+	if !m.consider("三上ゆい", "みかみゆい") {
+		t.Error("matcher.consider rejected a close name")
+	}
+	if m.consider("三上ゆい", "みかみゆい") {
+		t.Error("matcher.consider accepted a name scoring the same as the best")
+	}
+	if !m.consider("三上悠亜", "ミカミ・ユア") || m.score != 1 {
+		t.Errorf("matcher.consider did not accept an exact match, score %v", m.score)
+	}
+}
+
+func TestClient_resolve(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, testPagedItems(t, 10))
+
+	opt := &ItemOptions{Hits: 3, Offset: 1}
+	m := &matcher{name: "item4"}
+	var pages int
+	err := client.resolve(ctx, opt, m, func(ctx context.Context) (*Response, error) {
+		pages++
+		is, r, err := client.Items.List(ctx, opt)
+		for _, i := range is {
+			m.consider(i.ContentID)
+		}
+		return r, err
+	})
+	if err != nil {
+		t.Fatalf("Client.resolve returned error: %v", err)
+	}
+	if pages != 2 {
+		t.Errorf("Client.resolve fetched %d pages, expected to stop at the exact match on page 2", pages)
+	}
+
+	opt = &ItemOptions{Hits: 3, Offset: 1}
+	m = &matcher{name: "該当なし"}
+	err = client.resolve(ctx, opt, m, func(ctx context.Context) (*Response, error) {
+		_, r, err := client.Items.List(ctx, opt)
+		return r, err
+	})
+	if err != ErrNotResolved {
+		t.Errorf("Client.resolve returned %v, expected %v", err, ErrNotResolved)
+	}
+}
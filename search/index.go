@@ -0,0 +1,324 @@
+// Package search is an in-process full-text index over items,
+// answering keyword queries locally instead of calling ItemList with Keyword.
+//
+// Titles, comments and the names in ItemInfo are split into tokens by Tokenize,
+// which uses character bigrams for Japanese text. Indexed text also gets the single
+// characters, so that one character keywords match too. Results are ranked by TF-IDF,
+// with title matches weighing more than ItemInfo names and those more than comments.
+//
+//	x := search.NewIndex()
+//	x.Add(items...)
+//	rs := x.Search(search.Query{Keyword: "温泉 旅行", Floor: "videoa", Limit: 20})
+//	err := x.SaveFile("items.idx")
+package search
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/usk81/go-dmm"
+)
+
+// Field weights
+const (
+	TitleWeight    = 3
+	ItemInfoWeight = 2
+	CommentWeight  = 1
+)
+
+// formatVersion is the version of the file format written by Save
+const formatVersion = 1
+
+// Query is a search of the index.
+// Filters restrict the result to items of the floor, genre and maker, if they are set.
+type Query struct {
+	// Keyword is matched against every token it contains.
+	// An empty keyword matches every item passing the filters.
+	Keyword string
+	Service string
+	Floor   string
+	// GenreID and MakerID are ItemInfo IDs
+	GenreID string
+	MakerID string
+
+	// Limit is the maximum number of results, no limit if 0
+	Limit int
+}
+
+// Result is an item found by a search
+type Result struct {
+	Item  dmm.Item
+	Score float64
+}
+
+// Index is an inverted index over items. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]int
+}
+
+type document struct {
+	item dmm.Item
+	// tf are the weighted term frequencies of the tokens
+	tf     map[string]int
+	genres map[string]bool
+	makers map[string]bool
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{docs: map[string]*document{}, postings: map[string]map[string]int{}}
+}
+
+// Add indexes the items, replacing indexed items with the same ContentID
+func (x *Index) Add(is ...dmm.Item) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, i := range is {
+		x.remove(i.ContentID)
+		d := newDocument(i)
+		x.docs[i.ContentID] = d
+		for t, n := range d.tf {
+			p, ok := x.postings[t]
+			if !ok {
+				p = map[string]int{}
+				x.postings[t] = p
+			}
+			p[i.ContentID] = n
+		}
+	}
+}
+
+// Remove drops the items with the content IDs from the index
+func (x *Index) Remove(cids ...string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, cid := range cids {
+		x.remove(cid)
+	}
+}
+
+func (x *Index) remove(cid string) {
+	d, ok := x.docs[cid]
+	if !ok {
+		return
+	}
+	for t := range d.tf {
+		delete(x.postings[t], cid)
+		if len(x.postings[t]) == 0 {
+			delete(x.postings, t)
+		}
+	}
+	delete(x.docs, cid)
+}
+
+// Len returns the number of indexed items
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Item returns the indexed item with the content ID
+func (x *Index) Item(cid string) (dmm.Item, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	d, ok := x.docs[cid]
+	if !ok {
+		return dmm.Item{}, false
+	}
+	return d.item, true
+}
+
+// Search returns the items matching q, best first.
+// Items scoring the same, e.g. all of them when there is no keyword, are ordered by date, newest first.
+func (x *Index) Search(q Query) []Result {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var rs []Result
+	ts := unique(Tokenize(q.Keyword))
+	if len(ts) == 0 {
+		for _, d := range x.docs {
+			if d.matches(q) {
+				rs = append(rs, Result{Item: d.item})
+			}
+		}
+	} else {
+		rs = x.score(ts, q)
+	}
+
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Score != rs[j].Score {
+			return rs[i].Score > rs[j].Score
+		}
+		if rs[i].Item.Date != rs[j].Item.Date {
+			return rs[i].Item.Date > rs[j].Item.Date
+		}
+		return rs[i].Item.ContentID < rs[j].Item.ContentID
+	})
+	if q.Limit > 0 && len(rs) > q.Limit {
+		rs = rs[:q.Limit]
+	}
+	return rs
+}
+
+// score returns the documents containing every token
+func (x *Index) score(ts []string, q Query) []Result {
+	ps := make([]map[string]int, len(ts))
+	for i, t := range ts {
+		if ps[i] = x.postings[t]; len(ps[i]) == 0 {
+			return nil
+		}
+	}
+	// walk the rarest token's postings
+	sort.Slice(ps, func(i, j int) bool { return len(ps[i]) < len(ps[j]) })
+
+	n := float64(len(x.docs))
+	var rs []Result
+	for cid := range ps[0] {
+		d := x.docs[cid]
+		if !d.matches(q) {
+			continue
+		}
+		score := 0.0
+		for _, p := range ps {
+			tf, ok := p[cid]
+			if !ok {
+				score = -1
+				break
+			}
+			score += (1 + math.Log(float64(tf))) * math.Log(1+n/float64(len(p)))
+		}
+		if score >= 0 {
+			rs = append(rs, Result{Item: d.item, Score: score})
+		}
+	}
+	return rs
+}
+
+// Save writes the indexed items to w.
+// The postings are rebuilt by Load, which keeps the file small and independent of the tokenizer.
+func (x *Index) Save(w io.Writer) error {
+	x.mu.RLock()
+	is := make([]dmm.Item, 0, len(x.docs))
+	for _, d := range x.docs {
+		is = append(is, d.item)
+	}
+	x.mu.RUnlock()
+	sort.Slice(is, func(i, j int) bool { return is[i].ContentID < is[j].ContentID })
+
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(snapshot{Version: formatVersion, Items: is}); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Load adds the items written by Save to the index
+func (x *Index) Load(r io.Reader) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	var s snapshot
+	if err := json.NewDecoder(zr).Decode(&s); err != nil {
+		return err
+	}
+	if s.Version != formatVersion {
+		return fmt.Errorf("search: unsupported index version %d", s.Version)
+	}
+	x.Add(s.Items...)
+	return nil
+}
+
+// SaveFile writes the index to the file at path, replacing it
+func (x *Index) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := x.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadFile returns the index saved to the file at path
+func LoadFile(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	x := NewIndex()
+	if err := x.Load(f); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type snapshot struct {
+	Version int        `json:"version"`
+	Items   []dmm.Item `json:"items"`
+}
+
+func newDocument(i dmm.Item) *document {
+	d := &document{item: i, tf: map[string]int{}, genres: map[string]bool{}, makers: map[string]bool{}}
+	add := func(s string, w int) {
+		for _, t := range tokenizeDocument(s) {
+			d.tf[t] += w
+		}
+	}
+	add(i.Title, TitleWeight)
+	add(i.Comment, CommentWeight)
+	for key := range i.ItemInfo {
+		for _, c := range i.Components(key) {
+			add(c.Name, ItemInfoWeight)
+		}
+	}
+	for _, c := range i.Components(dmm.ItemInfoGenre) {
+		d.genres[c.ID.String()] = true
+	}
+	for _, c := range i.Components(dmm.ItemInfoMaker) {
+		d.makers[c.ID.String()] = true
+	}
+	return d
+}
+
+func (d *document) matches(q Query) bool {
+	switch {
+	case q.Service != "" && d.item.ServiceCode != q.Service:
+		return false
+	case q.Floor != "" && d.item.FloorCode != q.Floor:
+		return false
+	case q.GenreID != "" && !d.genres[q.GenreID]:
+		return false
+	case q.MakerID != "" && !d.makers[q.MakerID]:
+		return false
+	}
+	return true
+}
+
+func unique(ts []string) []string {
+	seen := map[string]bool{}
+	var r []string
+	for _, t := range ts {
+		if !seen[t] {
+			seen[t] = true
+			r = append(r, t)
+		}
+	}
+	return r
+}
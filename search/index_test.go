@@ -0,0 +1,140 @@
+package search
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/usk81/generic/v2"
+	"github.com/usk81/go-dmm"
+)
+
+func component(id int, name string) dmm.ItemComponent {
+	return dmm.ItemComponent{ID: generic.MustString(id), Name: name}
+}
+
+func testIndex() *Index {
+	x := NewIndex()
+	x.Add(
+		dmm.Item{
+			ContentID: "a1", FloorCode: "videoa", ServiceCode: "digital", Date: "2020-01-01 10:00:00",
+			Title: "温泉旅行", Comment: "週末の旅",
+			ItemInfo: map[string][]dmm.ItemComponent{
+				dmm.ItemInfoGenre: {component(1, "旅行")},
+				dmm.ItemInfoMaker: {component(10, "メーカーA")},
+				dmm.ItemInfoActress: {
+					component(100, "愛川"),
+					{ID: generic.MustString("100_ruby"), Name: "あいかわ"},
+					{ID: generic.MustString("100_classify"), Name: "av"},
+				},
+			},
+		},
+		dmm.Item{
+			ContentID: "a2", FloorCode: "videoa", ServiceCode: "digital", Date: "2020-01-02 10:00:00",
+			Title: "夏の思い出", Comment: "温泉旅行に行きました",
+			ItemInfo: map[string][]dmm.ItemComponent{
+				dmm.ItemInfoGenre: {component(2, "ドラマ")},
+				dmm.ItemInfoMaker: {component(11, "メーカーB")},
+			},
+		},
+		dmm.Item{
+			ContentID: "b1", FloorCode: "comic", ServiceCode: "ebook", Date: "2020-01-03 10:00:00",
+			Title: "旅行記",
+			ItemInfo: map[string][]dmm.ItemComponent{
+				dmm.ItemInfoGenre: {component(1, "旅行")},
+			},
+		},
+	)
+	return x
+}
+
+func contentIDs(rs []Result) []string {
+	var cids []string
+	for _, r := range rs {
+		cids = append(cids, r.Item.ContentID)
+	}
+	return cids
+}
+
+func TestIndex_Search(t *testing.T) {
+	x := testIndex()
+	cases := []struct {
+		q        Query
+		expected []string
+	}{
+		{Query{Keyword: "温泉旅行"}, []string{"a1", "a2"}},
+		{Query{Keyword: "旅行"}, []string{"b1", "a1", "a2"}},
+		{Query{Keyword: "メーカーB"}, []string{"a2"}},
+		{Query{Keyword: "存在しない"}, nil},
+		{Query{Keyword: "泉"}, []string{"a1", "a2"}},
+		{Query{Keyword: "愛"}, []string{"a1"}},
+		{Query{Keyword: "あいかわ"}, nil},
+		{Query{Keyword: "av"}, nil},
+		{Query{Keyword: "旅行", Floor: "videoa"}, []string{"a1", "a2"}},
+		{Query{Keyword: "旅行", Service: "ebook"}, []string{"b1"}},
+		{Query{Keyword: "旅行", GenreID: "1"}, []string{"b1", "a1"}},
+		{Query{MakerID: "11"}, []string{"a2"}},
+		{Query{}, []string{"b1", "a2", "a1"}},
+		{Query{Limit: 1}, []string{"b1"}},
+	}
+	for _, c := range cases {
+		if got := contentIDs(x.Search(c.q)); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Index.Search(%+v) returned %v, expected %v", c.q, got, c.expected)
+		}
+	}
+}
+
+func TestIndex_Add_replace(t *testing.T) {
+	x := testIndex()
+	x.Add(dmm.Item{ContentID: "a1", Title: "別のタイトル"})
+
+	if x.Len() != 3 {
+		t.Errorf("Index.Len returned %d, expected 3", x.Len())
+	}
+	if got := contentIDs(x.Search(Query{Keyword: "温泉"})); !reflect.DeepEqual(got, []string{"a2"}) {
+		t.Errorf("Index.Search returned %v, expected [a2]", got)
+	}
+	if got := contentIDs(x.Search(Query{Keyword: "タイトル"})); !reflect.DeepEqual(got, []string{"a1"}) {
+		t.Errorf("Index.Search returned %v, expected [a1]", got)
+	}
+
+	x.Remove("a1", "unknown")
+	if _, ok := x.Item("a1"); ok {
+		t.Error("Index.Item returned a removed item")
+	}
+	if got := x.Search(Query{Keyword: "タイトル"}); len(got) != 0 {
+		t.Errorf("Index.Search returned %v, expected nothing", contentIDs(got))
+	}
+}
+
+func TestIndex_Save(t *testing.T) {
+	x := testIndex()
+	dir, err := ioutil.TempDir("", "search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "items.idx")
+	if err := x.SaveFile(path); err != nil {
+		t.Fatalf("Index.SaveFile returned error: %v", err)
+	}
+
+	y, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if y.Len() != x.Len() {
+		t.Errorf("loaded index has %d items, expected %d", y.Len(), x.Len())
+	}
+	q := Query{Keyword: "旅行", GenreID: "1"}
+	if got, expected := y.Search(q), x.Search(q); !reflect.DeepEqual(got, expected) {
+		t.Errorf("loaded Index.Search returned %+v, expected %+v", got, expected)
+	}
+
+	if err := NewIndex().Load(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Error("Index.Load of garbage returned no error")
+	}
+}
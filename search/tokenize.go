@@ -0,0 +1,83 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits s into search tokens.
+//
+// Text is lower-cased and full-width ASCII is folded to half-width. Runs of Latin letters
+// and digits become one token each; runs of any other letters (kanji, kana, hangul, ...)
+// become overlapping character bigrams, or a single token if the run is one character long.
+// Everything else separates tokens.
+func Tokenize(s string) []string {
+	return tokenize(s, false)
+}
+
+// tokenizeDocument is Tokenize adding the single characters of non-Latin runs,
+// so that a one character keyword matches longer runs in indexed text
+func tokenizeDocument(s string) []string {
+	return tokenize(s, true)
+}
+
+func tokenize(s string, unigrams bool) []string {
+	var ts []string
+	var run []rune
+	latin := false
+	flush := func() {
+		switch {
+		case len(run) == 0:
+		case latin || len(run) == 1:
+			ts = append(ts, string(run))
+		default:
+			for i := 0; i+1 < len(run); i++ {
+				ts = append(ts, string(run[i:i+2]))
+			}
+			if unigrams {
+				for _, r := range run {
+					ts = append(ts, string(r))
+				}
+			}
+		}
+		run = run[:0]
+	}
+
+	for _, r := range strings.ToLower(foldWidth(s)) {
+		switch {
+		case isLatin(r):
+			if !latin {
+				flush()
+			}
+			latin = true
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if latin {
+				flush()
+			}
+			latin = false
+			run = append(run, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return ts
+}
+
+func isLatin(r rune) bool {
+	return r < 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// foldWidth maps full-width ASCII variants and the ideographic space to ASCII
+func foldWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '！' && r <= '～':
+			return r - 0xfee0
+		case r == '　':
+			return ' '
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,44 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected []string
+	}{
+		{"", nil},
+		{"Hello World", []string{"hello", "world"}},
+		{"ＡＢＣ１２３", []string{"abc123"}},
+		{"温泉旅行", []string{"温泉", "泉旅", "旅行"}},
+		{"温泉　旅行", []string{"温泉", "旅行"}},
+		{"新作DVD発売", []string{"新作", "dvd", "発売"}},
+		{"私", []string{"私"}},
+		{"スーパー!", []string{"スー", "ーパ", "パー"}},
+		{"【限定】2本", []string{"限定", "2", "本"}},
+	}
+	for _, c := range cases {
+		if got := Tokenize(c.in); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Tokenize(%q) returned %q, expected %q", c.in, got, c.expected)
+		}
+	}
+}
+
+func TestTokenizeDocument(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected []string
+	}{
+		{"温泉", []string{"温泉", "温", "泉"}},
+		{"愛のDVD", []string{"愛の", "愛", "の", "dvd"}},
+		{"私", []string{"私"}},
+	}
+	for _, c := range cases {
+		if got := tokenizeDocument(c.in); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("tokenizeDocument(%q) returned %q, expected %q", c.in, got, c.expected)
+		}
+	}
+}
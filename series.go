@@ -64,6 +64,13 @@ type Series struct {
 	FloorCode   string
 }
 
+// SeriesIndex is an index of series by initial
+type SeriesIndex struct {
+	// Initials are the indexed initials in gojūon order
+	Initials []string
+	Series   map[string][]Series
+}
+
 // SeriesOptions specifies the optional parameters to various List methods
 type SeriesOptions struct {
 	APIID       string `json:"api_id" url:"api_id"`
@@ -88,20 +95,21 @@ type internalSeriesOptions struct {
 }
 
 func (r *seriesRoot) populatePageValues(res *Response) {
+	res.ResultStatus = r.Result.Status.Int()
 	res.FirstPosition = r.Result.FirstPosition.Int()
 	res.ResultCount = r.Result.ResultCount.Int()
 	res.TotalCount = r.Result.TotalCount.Int()
 	res.Parameters = r.Request.Parameters.Convert()
 }
 
-func (s *SeriesServiceOp) list(ctx context.Context, path string) (seriesResult, *Response, error) {
+func (s *SeriesServiceOp) list(ctx context.Context, path string, opt ListOptions) (seriesResult, *Response, error) {
 	req, err := s.client.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return seriesResult{}, nil, err
 	}
 
 	var root seriesRoot
-	resp, err := s.client.Do(ctx, req, &root)
+	resp, err := s.client.do(ctx, &Call{Request: req, Options: listOptions(opt), Value: &root})
 	if err != nil {
 		return seriesResult{}, resp, err
 	}
@@ -126,7 +134,7 @@ func (s *SeriesServiceOp) List(ctx context.Context, opt *SeriesOptions) ([]Serie
 	}
 	var res seriesResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return nil, r, err
 	}
@@ -158,7 +166,7 @@ func (s *SeriesServiceOp) Unmarshal(ctx context.Context, opt *SeriesOptions, out
 	}
 	var res seriesResult
 	var r *Response
-	res, r, err = s.list(ctx, path)
+	res, r, err = s.list(ctx, path, opt)
 	if err != nil {
 		return r, err
 	}
@@ -171,6 +179,66 @@ func (s *SeriesServiceOp) Unmarshal(ctx context.Context, opt *SeriesOptions, out
 	return r, err
 }
 
+// ResolveSeries returns the series of the floor whose name or reading best matches name, as scored by kana.Match,
+// searching every page of opt. ErrNotResolved is returned if no series scores ResolveThreshold.
+func ResolveSeries(ctx context.Context, c *Client, opt *SeriesOptions, name string) (Series, *Response, error) {
+	var o SeriesOptions
+	if opt != nil {
+		o = *opt
+	}
+	resolvePage(&o.Hits, &o.Offset)
+
+	var best Series
+	var resp *Response
+	m := &matcher{name: name}
+	err := c.resolve(ctx, &o, m, func(ctx context.Context) (*Response, error) {
+		es, r, err := c.Series.List(ctx, &o)
+		resp = r
+		for _, e := range es {
+			if m.consider(e.Name, e.Ruby) {
+				best = e
+			}
+		}
+		return r, err
+	})
+	if err != nil {
+		return Series{}, resp, err
+	}
+	return best, resp, nil
+}
+
+// IndexSeries fetches every page of series of opt for each initial of the gojūon table, or only for opt.Initial if it is set,
+// running at most concurrency initials at once (DefaultIndexConcurrency if 0).
+// An *InitialError is returned if opt.Initial is not a kana.
+func IndexSeries(ctx context.Context, c *Client, opt *SeriesOptions, concurrency int) (SeriesIndex, error) {
+	var o SeriesOptions
+	if opt != nil {
+		o = *opt
+	}
+	initials, lists, err := fetchIndex(ctx, o.Initial, concurrency, func(ctx context.Context, initial string) (interface{}, error) {
+		po := o
+		po.Initial = initial
+		indexPage(&po.Hits, &po.Offset)
+
+		var es []Series
+		err := c.Paginate(ctx, &po, func(ctx context.Context) (*Response, error) {
+			page, r, err := c.Series.List(ctx, &po)
+			es = append(es, page...)
+			return r, err
+		})
+		return es, err
+	})
+	if err != nil {
+		return SeriesIndex{}, err
+	}
+
+	idx := SeriesIndex{Initials: initials, Series: make(map[string][]Series, len(lists))}
+	for initial, l := range lists {
+		idx.Series[initial] = l.([]Series)
+	}
+	return idx, nil
+}
+
 // Next updates offset
 func (o *SeriesOptions) Next() (err error) {
 	o.Offset, err = nextOffset(o.Hits, o.Offset)
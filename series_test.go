@@ -457,3 +457,28 @@ func TestSeriesOptions_GetOffset(t *testing.T) {
 		t.Errorf("GetHits returned %d", offset)
 	}
 }
+
+func TestResolveSeries(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+seriesBasePath, func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		fmt.Fprint(w, testSeriesRequest)
+	})
+
+	for _, name := range []string{`アイドル ザーメン`} {
+		actual, _, err := ResolveSeries(ctx, client, &SeriesOptions{FloorID: "43"}, name)
+		if err != nil {
+			t.Errorf("ResolveSeries(%q) returned error: %v", name, err)
+			continue
+		}
+		if actual.SeriesID != `441` {
+			t.Errorf("ResolveSeries(%q) returned %+v, expected SeriesID 441", name, actual)
+		}
+	}
+
+	if _, _, err := ResolveSeries(ctx, client, &SeriesOptions{FloorID: "43"}, `該当なし`); err != ErrNotResolved {
+		t.Errorf("ResolveSeries returned %v, expected %v", err, ErrNotResolved)
+	}
+}
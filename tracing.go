@@ -0,0 +1,176 @@
+package dmm
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/url"
+)
+
+// Tracer starts spans around API calls.
+// OpenTelemetry is supported by the adapter in the dmmotel module.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key/value pair annotating a Span.
+// Value is a string, int, int64, float64 or bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attribute keys set on spans
+const (
+	AttrEndpoint      = "dmm.endpoint"
+	AttrSite          = "dmm.site"
+	AttrService       = "dmm.service"
+	AttrFloor         = "dmm.floor"
+	AttrHits          = "dmm.hits"
+	AttrOffset        = "dmm.offset"
+	AttrPage          = "dmm.page"
+	AttrResultStatus  = "dmm.result_status"
+	AttrResultCount   = "dmm.result_count"
+	AttrTotalCount    = "dmm.total_count"
+	AttrFirstPosition = "dmm.first_position"
+	AttrHTTPStatus    = "http.status_code"
+	AttrErrorType     = "error.type"
+)
+
+// Error classifications set as AttrErrorType
+const (
+	ErrorTypeAPI      = "api"
+	ErrorTypeNetwork  = "network"
+	ErrorTypeDecode   = "decode"
+	ErrorTypeCanceled = "canceled"
+	ErrorTypeOther    = "other"
+)
+
+type noopTracer struct{}
+
+type noopSpan struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
+
+// SetTracer is a client option for tracing every API call and pagination with t.
+func SetTracer(t Tracer) ClientOpt {
+	return func(c *Client) error {
+		c.tracer = t
+		c.Use(TracingMiddleware(t))
+		return nil
+	}
+}
+
+func (c *Client) getTracer() Tracer {
+	if c.tracer == nil {
+		return noopTracer{}
+	}
+	return c.tracer
+}
+
+// TracingMiddleware returns a Middleware starting a span per API call, annotated with
+// the endpoint, the request parameters, the result counts and the error classification.
+func TracingMiddleware(t Tracer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			ep := endpoint(call.Request.URL)
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx, span := t.Start(ctx, ep)
+			defer span.End()
+
+			span.SetAttributes(Attribute{AttrEndpoint, ep})
+			span.SetAttributes(optionAttributes(call.Options)...)
+
+			resp, err := next(ctx, call)
+			if resp != nil {
+				if resp.Response != nil {
+					span.SetAttributes(Attribute{AttrHTTPStatus, resp.StatusCode})
+				}
+				span.SetAttributes(
+					Attribute{AttrResultStatus, resp.ResultStatus},
+					Attribute{AttrResultCount, resp.ResultCount},
+					Attribute{AttrTotalCount, resp.TotalCount},
+					Attribute{AttrFirstPosition, resp.FirstPosition},
+				)
+			}
+			if err != nil {
+				span.SetAttributes(Attribute{AttrErrorType, ErrorType(err)})
+				span.RecordError(err)
+			}
+			return resp, err
+		}
+	}
+}
+
+func optionAttributes(opt ListOptions) []Attribute {
+	if opt == nil {
+		return nil
+	}
+	var as []Attribute
+	switch o := opt.(type) {
+	case *ItemOptions:
+		as = appendAttribute(as, AttrSite, o.Site)
+		as = appendAttribute(as, AttrService, o.Service)
+		as = appendAttribute(as, AttrFloor, o.Floor)
+	case *GenreOptions:
+		as = appendAttribute(as, AttrFloor, o.FloorID)
+	case *MakerOptions:
+		as = appendAttribute(as, AttrFloor, o.FloorID)
+	case *SeriesOptions:
+		as = appendAttribute(as, AttrFloor, o.FloorID)
+	case *AuthorOptions:
+		as = appendAttribute(as, AttrFloor, o.FloorID)
+	}
+	if h := opt.GetHits(); h != 0 {
+		as = append(as, Attribute{AttrHits, h})
+	}
+	if o := opt.GetOffset(); o != 0 {
+		as = append(as, Attribute{AttrOffset, o})
+	}
+	return as
+}
+
+func appendAttribute(as []Attribute, key, value string) []Attribute {
+	if value == "" {
+		return as
+	}
+	return append(as, Attribute{key, value})
+}
+
+// ErrorType classifies an error returned by the client
+func ErrorType(err error) string {
+	// context.DeadlineExceeded is a net.Error too
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return ErrorTypeCanceled
+	}
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case *ErrorResponse:
+		return ErrorTypeAPI
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		return ErrorTypeDecode
+	case *url.Error:
+		return ErrorType(e.Err)
+	case net.Error:
+		return ErrorTypeNetwork
+	}
+	return ErrorTypeOther
+}
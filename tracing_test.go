@@ -0,0 +1,198 @@
+package dmm
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testSpanKey struct{}
+
+type testSpan struct {
+	name   string
+	parent *testSpan
+	attrs  map[string]interface{}
+	errs   []error
+	ended  bool
+}
+
+func (s *testSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+// testTracer is an in-memory Tracer keeping every span it started
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	s := &testSpan{name: name, parent: parent, attrs: map[string]interface{}{}}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, testSpanKey{}, s), s
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, testPagedItems(t, 5))
+
+	tr := &testTracer{}
+	if err := SetTracer(tr)(client); err != nil {
+		t.Fatalf("SetTracer returned error: %v", err)
+	}
+
+	opt := &ItemOptions{Site: SiteAdult, Service: "digital", Floor: "videoa", Hits: 3, Offset: 1}
+	if _, _, err := client.Items.List(ctx, opt); err != nil {
+		t.Fatalf("Items.List returned error: %v", err)
+	}
+
+	if len(tr.spans) != 1 {
+		t.Fatalf("started %d spans, expected 1", len(tr.spans))
+	}
+	s := tr.spans[0]
+	if s.name != itemBasePath || !s.ended {
+		t.Errorf("span = %+v, expected an ended span named %s", s, itemBasePath)
+	}
+	expected := map[string]interface{}{
+		AttrEndpoint:      itemBasePath,
+		AttrSite:          SiteAdult,
+		AttrService:       "digital",
+		AttrFloor:         "videoa",
+		AttrHits:          3,
+		AttrOffset:        1,
+		AttrHTTPStatus:    http.StatusOK,
+		AttrResultStatus:  200,
+		AttrResultCount:   3,
+		AttrTotalCount:    5,
+		AttrFirstPosition: 1,
+	}
+	for k, v := range expected {
+		if s.attrs[k] != v {
+			t.Errorf("attribute %s = %v, expected %v", k, s.attrs[k], v)
+		}
+	}
+}
+
+func TestTracingMiddleware_error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"result":{"status":400,"message":"LOGIN ERROR"}}`)
+	})
+
+	tr := &testTracer{}
+	client.Use(TracingMiddleware(tr))
+
+	if _, _, err := client.Items.List(ctx, nil); err == nil {
+		t.Fatal("Expected error to be returned")
+	}
+
+	s := tr.spans[0]
+	if s.attrs[AttrErrorType] != ErrorTypeAPI {
+		t.Errorf("attribute %s = %v, expected %v", AttrErrorType, s.attrs[AttrErrorType], ErrorTypeAPI)
+	}
+	if len(s.errs) != 1 {
+		t.Errorf("recorded %d errors, expected 1", len(s.errs))
+	}
+}
+
+func TestTracing_Paginate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+itemBasePath, testPagedItems(t, 5))
+
+	tr := &testTracer{}
+	if err := SetTracer(tr)(client); err != nil {
+		t.Fatalf("SetTracer returned error: %v", err)
+	}
+
+	opt := &ItemOptions{Hits: 3, Offset: 1}
+	err := client.Paginate(ctx, opt, func(ctx context.Context) (*Response, error) {
+		_, r, err := client.Items.List(ctx, opt)
+		return r, err
+	})
+	if err != nil {
+		t.Fatalf("Client.Paginate returned error: %v", err)
+	}
+
+	// paginate -> page -> request, twice
+	if len(tr.spans) != 5 {
+		t.Fatalf("started %d spans, expected 5", len(tr.spans))
+	}
+	root := tr.spans[0]
+	if root.name != "dmm.paginate" || root.parent != nil || root.attrs[AttrPage] != 2 {
+		t.Errorf("root span = %+v", root)
+	}
+	for i, page := range []*testSpan{tr.spans[1], tr.spans[3]} {
+		if page.name != "dmm.page" || page.parent != root || page.attrs[AttrPage] != i+1 {
+			t.Errorf("page span %d = %+v", i+1, page)
+		}
+		call := tr.spans[2+i*2]
+		if call.name != itemBasePath || call.parent != page {
+			t.Errorf("call span %d = %+v", i+1, call)
+		}
+	}
+	for _, s := range tr.spans {
+		if !s.ended {
+			t.Errorf("span %s was not ended", s.name)
+		}
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected string
+	}{
+		{nil, ""},
+		{&ErrorResponse{}, ErrorTypeAPI},
+		{context.Canceled, ErrorTypeCanceled},
+		{&url.Error{Op: "Get", URL: "https://api.dmm.com/", Err: context.DeadlineExceeded}, ErrorTypeCanceled},
+		{&url.Error{Op: "Get", URL: "https://api.dmm.com/", Err: &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}}, ErrorTypeNetwork},
+		{fmt.Errorf("something"), ErrorTypeOther},
+	}
+	for _, c := range cases {
+		if got := ErrorType(c.err); got != c.expected {
+			t.Errorf("ErrorType(%v) = %q, expected %q", c.err, got, c.expected)
+		}
+	}
+}
+
+func TestErrorType_timeout(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(`/`+actressBasePath, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	c, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, _, err := client.Actresses.List(c, nil)
+	if got := ErrorType(err); got != ErrorTypeCanceled {
+		t.Errorf("ErrorType(%v) = %q, expected %q", err, got, ErrorTypeCanceled)
+	}
+}